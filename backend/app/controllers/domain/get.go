@@ -29,14 +29,15 @@ func GetDomains(c *gin.Context) {
 	var response []DomainResponse
 	for _, domain := range domains {
 		response = append(response, DomainResponse{
-			ID:          domain.ID,
-			WorkspaceID: domain.WorkspaceID,
-			Domain:      domain.Domain,
-			Verified:    domain.Verified,
-			VerifyToken: domain.VerifyToken,
-			VerifiedAt:  domain.VerifiedAt,
-			CreatedAt:   domain.CreatedAt,
-			UpdatedAt:   domain.UpdatedAt,
+			ID:              domain.ID,
+			WorkspaceID:     domain.WorkspaceID,
+			Domain:          domain.Domain,
+			Verified:        domain.Verified,
+			VerifyToken:     domain.VerifyToken,
+			VerifiedAt:      domain.VerifiedAt,
+			ShortCodeLength: domain.ShortCodeLength,
+			CreatedAt:       domain.CreatedAt,
+			UpdatedAt:       domain.UpdatedAt,
 		})
 	}
 
@@ -74,13 +75,14 @@ func GetDomain(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, &DomainResponse{
-		ID:          domain.ID,
-		WorkspaceID: domain.WorkspaceID,
-		Domain:      domain.Domain,
-		Verified:    domain.Verified,
-		VerifyToken: domain.VerifyToken,
-		VerifiedAt:  domain.VerifiedAt,
-		CreatedAt:   domain.CreatedAt,
-		UpdatedAt:   domain.UpdatedAt,
+		ID:              domain.ID,
+		WorkspaceID:     domain.WorkspaceID,
+		Domain:          domain.Domain,
+		Verified:        domain.Verified,
+		VerifyToken:     domain.VerifyToken,
+		VerifiedAt:      domain.VerifiedAt,
+		ShortCodeLength: domain.ShortCodeLength,
+		CreatedAt:       domain.CreatedAt,
+		UpdatedAt:       domain.UpdatedAt,
 	})
 }
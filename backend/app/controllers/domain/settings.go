@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/shortcode"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// UpdateDomainSettings updates the link defaults of a domain
+func UpdateDomainSettings(c *gin.Context) {
+	// Get domain ID from parameters
+	domainIDStr := c.Param("domainID")
+	domainID, err := strconv.ParseUint(domainIDStr, 10, 64)
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid domain ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	var req DomainSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request format", utils.ErrBadRequest, err.Error())
+		return
+	}
+
+	if *req.ShortCodeLength != 0 {
+		if err := shortcode.ValidateLength(*req.ShortCodeLength); err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "short code "+err.Error(), utils.ErrBadRequest, nil)
+			return
+		}
+	}
+
+	// Get the domain to check ownership
+	domain, result := queries.GetDomainByID(domainID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Domain not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	// Ensure the domain belongs to the workspace
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	if domain.WorkspaceID == nil || *domain.WorkspaceID != workspaceID.(uint64) {
+		utils.FullyResponse(c, http.StatusForbidden, "You don't have permission to update this domain", utils.ErrForbidden, nil)
+		return
+	}
+
+	// Get workspace role from context
+	workspaceRole, exists := c.Get("workspaceRole")
+	if !exists || workspaceRole != "owner" {
+		utils.FullyResponse(c, http.StatusForbidden, "Only workspace owners can update domain settings", utils.ErrForbidden, nil)
+		return
+	}
+
+	domain.ShortCodeLength = *req.ShortCodeLength
+	result = queries.UpdateDomain(*domain)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to update domain settings", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Domain settings updated successfully", nil, &DomainResponse{
+		ID:              domain.ID,
+		WorkspaceID:     domain.WorkspaceID,
+		Domain:          domain.Domain,
+		Verified:        domain.Verified,
+		VerifiedAt:      domain.VerifiedAt,
+		ShortCodeLength: domain.ShortCodeLength,
+		CreatedAt:       domain.CreatedAt,
+		UpdatedAt:       domain.UpdatedAt,
+	})
+}
@@ -9,12 +9,18 @@ type DomainRequest struct {
 
 // DomainResponse represents a domain in API responses
 type DomainResponse struct {
-	ID          uint64     `json:"id,string"`
-	WorkspaceID *uint64    `json:"workspace_id,string,omitempty"`
-	Domain      string     `json:"domain"`
-	Verified    bool       `json:"verified"`
-	VerifyToken string     `json:"verify_token,omitempty"`
-	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID              uint64     `json:"id,string"`
+	WorkspaceID     *uint64    `json:"workspace_id,string,omitempty"`
+	Domain          string     `json:"domain"`
+	Verified        bool       `json:"verified"`
+	VerifyToken     string     `json:"verify_token,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	ShortCodeLength int        `json:"short_code_length,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// DomainSettingsRequest represents a request to update the link defaults of a domain
+type DomainSettingsRequest struct {
+	ShortCodeLength *int `json:"short_code_length" binding:"required"` // 0 uses the workspace setting
 }
@@ -2,10 +2,12 @@ package shortener
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
 	"github.com/yorukot/zipt/app/queries"
 	"github.com/yorukot/zipt/pkg/logger"
 	"github.com/yorukot/zipt/pkg/utils"
@@ -21,6 +23,10 @@ const (
 	Device   AnalyticsDataType = "device"
 	Browser  AnalyticsDataType = "browser"
 	OS       AnalyticsDataType = "os"
+	GeoRule  AnalyticsDataType = "geo_rule"
+	Variant  AnalyticsDataType = "variant"
+	Language AnalyticsDataType = "language"
+	Source   AnalyticsDataType = "source"
 )
 
 // GetURLAnalytics returns analytics data for a specific URL
@@ -108,12 +114,18 @@ func GetURLAnalytics(c *gin.Context) {
 		return
 	}
 
+	// Optionally limit the breakdown to a single variant
+	filters := make(map[string]string)
+	if variant := c.Query("variant"); variant != "" {
+		filters["variant"] = variant
+	}
+
 	// Map to store all analytics results
 	analyticsData := make(map[AnalyticsDataType][]queries.AnalyticsDataPoint)
 
 	// Function to fetch analytics data by type
 	fetchAnalytics := func(dataType AnalyticsDataType) error {
-		data, err := queries.GetDiffrentTypeAnalyticsData(url.ID, 1, timeAccuracy, string(dataType), parsedStartDate, parsedEndDate)
+		data, err := queries.GetDiffrentTypeAnalyticsData(url.ID, 1, timeAccuracy, string(dataType), filters, parsedStartDate, parsedEndDate)
 		if err != nil {
 			return err
 		}
@@ -122,14 +134,14 @@ func GetURLAnalytics(c *gin.Context) {
 	}
 
 	// Fetch all analytics data types
-	for _, dataType := range []AnalyticsDataType{Referrer, Country, City, Device, Browser, OS} {
+	for _, dataType := range []AnalyticsDataType{Referrer, Country, City, Device, Browser, OS, GeoRule, Variant, Language, Source} {
 		if err := fetchAnalytics(dataType); err != nil {
 			logger.Log.Sugar().Errorf("Error retrieving analytics data for %s: %v", dataType, err)
 		}
 	}
 
 	// Ensure all analytics fields are non-nil slices
-	for _, dataType := range []AnalyticsDataType{Referrer, Country, City, Device, Browser, OS} {
+	for _, dataType := range []AnalyticsDataType{Referrer, Country, City, Device, Browser, OS, GeoRule, Variant, Language, Source} {
 		if analyticsData[dataType] == nil {
 			analyticsData[dataType] = make([]queries.AnalyticsDataPoint, 0)
 		}
@@ -145,6 +157,7 @@ func GetURLAnalytics(c *gin.Context) {
 			"expires_at":   url.ExpiresAt,
 		},
 		"analytics": gin.H{
+			"filters":      filters,
 			"total_clicks": url.TotalClicks,
 			"referrer":     analyticsData[Referrer],
 			"country":      analyticsData[Country],
@@ -152,10 +165,22 @@ func GetURLAnalytics(c *gin.Context) {
 			"device":       analyticsData[Device],
 			"browser":      analyticsData[Browser],
 			"os":           analyticsData[OS],
+			"geo_rule":     analyticsData[GeoRule],
+			"variant":      analyticsData[Variant],
+			"language":     analyticsData[Language],
+			"source":       analyticsData[Source],
 		},
 	})
 }
 
+// DestinationMarker marks the time the destination of a URL changed on its time series
+type DestinationMarker struct {
+	Time        time.Time `json:"time"`
+	RevisionID  uint64    `json:"revision_id,string"`
+	Source      string    `json:"source"`
+	OriginalURL string    `json:"original_url"`
+}
+
 // GetURLTimeSeriesData returns time series data for a specific URL with optional filters
 // This endpoint requires authentication
 func GetURLTimeSeriesData(c *gin.Context) {
@@ -245,7 +270,7 @@ func GetURLTimeSeriesData(c *gin.Context) {
 	filters := make(map[string]string)
 
 	// Check for valid filter parameters
-	for _, field := range []string{"referrer", "country", "city", "device", "browser", "os"} {
+	for _, field := range []string{"referrer", "country", "city", "device", "browser", "os", "geo_rule", "variant", "language", "source"} {
 		if value := c.Query(field); value != "" {
 			filters[field] = value
 		}
@@ -259,6 +284,23 @@ func GetURLTimeSeriesData(c *gin.Context) {
 		timeSeriesData = []queries.TimeSeriesDataPoint{} // Return empty array instead of failing
 	}
 
+	// Mark where the destination changed, so shifts in the clicks can be explained
+	markers := []DestinationMarker{}
+	revisions, result := queries.GetURLRevisionsInRange(url.ID, parsedStartDate, parsedEndDate)
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Error retrieving URL revisions: %v", result.Error)
+	}
+	for _, revision := range revisions {
+		if slices.Contains(revision.ChangedFields, models.RevisionFieldOriginalURL) {
+			markers = append(markers, DestinationMarker{
+				Time:        revision.CreatedAt,
+				RevisionID:  revision.ID,
+				Source:      revision.Source,
+				OriginalURL: revision.OriginalURL,
+			})
+		}
+	}
+
 	// Determine the granularity description for frontend
 	var granularityDesc string
 	switch timeAccuracy {
@@ -280,6 +322,7 @@ func GetURLTimeSeriesData(c *gin.Context) {
 		},
 		"time_series": gin.H{
 			"data":        timeSeriesData,
+			"markers":     markers,
 			"granularity": granularityDesc,
 			"filters":     filters,
 			"date_range": gin.H{
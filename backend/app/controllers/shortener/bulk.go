@@ -0,0 +1,403 @@
+package shortener
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// maxBulkURLs is the maximum number of URLs a single bulk operation changes
+const maxBulkURLs = 1000
+
+// Bulk operations
+const (
+	BulkSetExpiry    = "set_expiry"
+	BulkChangeDomain = "change_domain"
+	BulkAddTags      = "add_tags"
+	BulkRemoveTags   = "remove_tags"
+	BulkMoveFolder   = "move_folder"
+	BulkDelete       = "delete"
+)
+
+// Per URL statuses of a bulk operation
+const (
+	bulkStatusOK     = "ok"
+	bulkStatusFailed = "failed"
+)
+
+// bulkExpiryConflict is the error of the URLs activating at or after the new expiry
+const bulkExpiryConflict = "activation time must be before the expiration time"
+
+// BulkOperationRequest represents the request body of a bulk operation.
+// The URLs are either listed by ID, or all URLs matching the list filters of the query string.
+type BulkOperationRequest struct {
+	URLIDs      []string   `json:"url_ids" binding:"omitempty,max=1000"`
+	AllMatching bool       `json:"all_matching"`
+	Operation   string     `json:"operation" binding:"required,oneof=set_expiry change_domain add_tags remove_tags move_folder delete"`
+	ExpiresAt   *time.Time `json:"expires_at"`   // set_expiry
+	ClearExpiry bool       `json:"clear_expiry"` // set_expiry, removes the expiration instead
+	DomainID    *uint64    `json:"domain_id"`    // change_domain, 0 is the default domain
+	TagIDs      []string   `json:"tag_ids"`      // add_tags and remove_tags
+	FolderID    string     `json:"folder_id"`    // move_folder, empty removes the URLs from their folder
+	DryRun      bool       `json:"dry_run"`      // Only report what would happen
+}
+
+// BulkURLResult reports the outcome of a bulk operation for a single URL
+type BulkURLResult struct {
+	URLID     uint64 `json:"url_id,string"`
+	ShortCode string `json:"short_code,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkOperationResponse reports the outcome of a bulk operation
+type BulkOperationResponse struct {
+	Operation string          `json:"operation"`
+	DryRun    bool            `json:"dry_run"`
+	Applied   bool            `json:"applied"`
+	Matched   int             `json:"matched"`
+	Failed    int             `json:"failed"`
+	Results   []BulkURLResult `json:"results"`
+}
+
+// BulkUpdateURLs applies one operation to many URLs of the workspace at once.
+// Nothing is changed unless the operation succeeds for every URL.
+func BulkUpdateURLs(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.FullyResponse(c, http.StatusUnauthorized, "Authentication required", utils.ErrUnauthorized, nil)
+		return
+	}
+
+	workspaceIDAny, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+	workspaceID := workspaceIDAny.(uint64)
+
+	var request BulkOperationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+
+	urls, results, err := getBulkURLs(c, workspaceID, &request)
+	if err != nil {
+		return
+	}
+
+	// Resolve and check what the operation needs once, then every URL
+	var tagIDs []uint64
+	var folderID *uint64
+	switch request.Operation {
+	case BulkSetExpiry:
+		// A forgotten expires_at must not remove the expiration of every URL
+		if (request.ExpiresAt == nil) != request.ClearExpiry {
+			errMsg := "set_expiry requires either expires_at or clear_expiry set to true"
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return
+		}
+		for _, url := range urls {
+			if request.ExpiresAt != nil && url.ActivatesAt != nil && !url.ActivatesAt.Before(*request.ExpiresAt) {
+				setBulkFailure(results, url, bulkExpiryConflict)
+			}
+		}
+
+	case BulkChangeDomain:
+		if err := checkBulkDomain(c, workspaceID, request.DomainID); err != nil {
+			return
+		}
+		if err := checkBulkShortCodes(urls, results, *request.DomainID); err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking short codes", utils.ErrGetData, err)
+			return
+		}
+
+	case BulkAddTags, BulkRemoveTags:
+		if len(request.TagIDs) == 0 {
+			utils.FullyResponse(c, http.StatusBadRequest, "tag_ids is required for this operation", utils.ErrBadRequest, nil)
+			return
+		}
+		tags, err := getRequestTags(c, request.TagIDs)
+		if err != nil {
+			return
+		}
+		for _, tag := range tags {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+
+	case BulkMoveFolder:
+		if folderID, err = getRequestFolder(c, request.FolderID); err != nil {
+			return
+		}
+	}
+
+	response := BulkOperationResponse{
+		Operation: request.Operation,
+		DryRun:    request.DryRun,
+		Matched:   len(results),
+		Results:   results,
+	}
+
+	ids := make([]uint64, 0, len(urls))
+	for _, url := range urls {
+		ids = append(ids, url.ID)
+	}
+	for _, result := range results {
+		if result.Status == bulkStatusFailed {
+			response.Failed++
+		}
+	}
+
+	if request.DryRun {
+		utils.FullyResponse(c, http.StatusOK, "Dry run, no URL was changed", nil, response)
+		return
+	}
+
+	if response.Failed > 0 {
+		respondBulkFailures(c, response)
+		return
+	}
+
+	if len(ids) > 0 {
+		// Expiry and domain changes are part of the URL history, stored with the change
+		var revisions []models.URLRevision
+		if request.Operation == BulkSetExpiry || request.Operation == BulkChangeDomain {
+			updated := make([]models.URL, len(urls))
+			for i, url := range urls {
+				updated[i] = url
+				if request.Operation == BulkSetExpiry {
+					updated[i].ExpiresAt = request.ExpiresAt
+				} else {
+					updated[i].DomainID = *request.DomainID
+				}
+			}
+			if revisions, _, err = buildURLRevisions(urls, updated, getContextUserID(c), models.RevisionSourceBulk, nil); err != nil {
+				utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking URL revisions", utils.ErrGetData, err)
+				return
+			}
+		}
+
+		switch request.Operation {
+		case BulkSetExpiry:
+			// The URLs are checked again while they are updated, they may have changed since
+			var conflicts []uint64
+			if conflicts, err = queries.BulkSetURLExpiryQueue(ids, request.ExpiresAt, revisions); errors.Is(err, queries.ErrBulkConflict) {
+				for _, url := range urls {
+					if slices.Contains(conflicts, url.ID) {
+						setBulkFailure(results, url, bulkExpiryConflict)
+						response.Failed++
+					}
+				}
+				respondBulkFailures(c, response)
+				return
+			}
+		case BulkChangeDomain:
+			err = queries.BulkUpdateURLsQueue(ids, map[string]interface{}{"domain_id": *request.DomainID}, revisions)
+		case BulkAddTags:
+			err = queries.BulkAddURLTagsQueue(ids, tagIDs)
+		case BulkRemoveTags:
+			err = queries.BulkRemoveURLTagsQueue(ids, tagIDs)
+		case BulkMoveFolder:
+			err = queries.BulkUpdateURLsQueue(ids, map[string]interface{}{"folder_id": folderID}, nil)
+		case BulkDelete:
+			err = queries.BulkDeleteURLsQueue(ids)
+		}
+		if queries.IsShortCodeConflict(err) {
+			errMsg := "No URL was changed, a short code is already in use on the target domain"
+			utils.FullyResponse(c, http.StatusConflict, errMsg, utils.ErrBadRequest, nil)
+			return
+		}
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error applying bulk operation", utils.ErrSaveData, err)
+			return
+		}
+	}
+
+	response.Applied = true
+	utils.FullyResponse(c, http.StatusOK, "Bulk operation applied", nil, response)
+}
+
+// respondBulkFailures responds that nothing was changed because the operation fails for some URLs
+func respondBulkFailures(c *gin.Context, response BulkOperationResponse) {
+	errMsg := fmt.Sprintf("No URL was changed, the operation fails for %d URLs", response.Failed)
+	utils.FullyResponse(c, http.StatusConflict, errMsg, utils.ErrBadRequest, response)
+}
+
+// getBulkURLs loads the URLs a bulk operation applies to, with a result for each of them.
+// URLs requested by ID but missing from the workspace get a failed result.
+// The error response has already been sent when an error is returned.
+func getBulkURLs(c *gin.Context, workspaceID uint64, request *BulkOperationRequest) ([]models.URL, []BulkURLResult, error) {
+	if request.AllMatching {
+		if len(request.URLIDs) > 0 {
+			errMsg := "url_ids and all_matching can't be used together"
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return nil, nil, errors.New(errMsg)
+		}
+
+		filter, err := getURLFilter(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		urls, result := queries.GetWorkspaceURLsByFilter(workspaceID, filter, maxBulkURLs+1)
+		if result.Error != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving URLs", utils.ErrGetData, result.Error)
+			return nil, nil, result.Error
+		}
+		if len(urls) > maxBulkURLs {
+			errMsg := fmt.Sprintf("the filters match more than %d URLs, narrow them down", maxBulkURLs)
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return nil, nil, errors.New(errMsg)
+		}
+
+		results := make([]BulkURLResult, len(urls))
+		for i, url := range urls {
+			results[i] = BulkURLResult{URLID: url.ID, ShortCode: url.ShortCode, Status: bulkStatusOK}
+		}
+		return urls, results, nil
+	}
+
+	if len(request.URLIDs) == 0 {
+		errMsg := "url_ids is required unless all_matching is set"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, nil, errors.New(errMsg)
+	}
+
+	ids := make([]uint64, 0, len(request.URLIDs))
+	seen := map[uint64]bool{}
+	for _, urlID := range request.URLIDs {
+		id, err := utils.StrToUint64(urlID)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid URL ID", utils.ErrBadRequest, urlID)
+			return nil, nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	// Like UpdateURL, only URLs of the workspace can be changed
+	urls, result := queries.GetWorkspaceURLsByIDs(workspaceID, ids)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving URLs", utils.ErrGetData, result.Error)
+		return nil, nil, result.Error
+	}
+
+	byID := make(map[uint64]int, len(urls))
+	for i, url := range urls {
+		byID[url.ID] = i
+	}
+
+	// Results follow the order of the request, the failed URLs can't be part of the operation
+	ordered := make([]models.URL, 0, len(urls))
+	results := make([]BulkURLResult, 0, len(ids))
+	for _, id := range ids {
+		i, ok := byID[id]
+		if !ok {
+			results = append(results, BulkURLResult{URLID: id, Status: bulkStatusFailed, Error: "short URL not found in this workspace"})
+			continue
+		}
+		ordered = append(ordered, urls[i])
+		results = append(results, BulkURLResult{URLID: id, ShortCode: urls[i].ShortCode, Status: bulkStatusOK})
+	}
+
+	return ordered, results, nil
+}
+
+// checkBulkDomain ensures the target domain of a domain change can be used by the workspace, like UpdateURL does.
+// The error response has already been sent when an error is returned.
+func checkBulkDomain(c *gin.Context, workspaceID uint64, domainID *uint64) error {
+	if domainID == nil {
+		errMsg := "domain_id is required for this operation"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return errors.New(errMsg)
+	}
+	if *domainID == 0 {
+		return nil
+	}
+
+	domain, result := queries.GetDomainByID(*domainID)
+	if result.Error != nil || domain == nil {
+		errMsg := "invalid domain ID"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return errors.New(errMsg)
+	}
+
+	if !domain.Verified {
+		errMsg := "domain has not been verified yet"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return errors.New(errMsg)
+	}
+
+	if domain.WorkspaceID != nil && *domain.WorkspaceID != workspaceID {
+		errMsg := "you don't have permission to use this domain"
+		utils.FullyResponse(c, http.StatusForbidden, errMsg, utils.ErrForbidden, nil)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+// checkBulkShortCodes marks the URLs whose short code is already taken on the target domain,
+// either by another URL or by an earlier URL of the same operation
+func checkBulkShortCodes(urls []models.URL, results []BulkURLResult, domainID uint64) error {
+	var shortCodes []string
+	ids := make([]uint64, 0, len(urls))
+	for _, url := range urls {
+		ids = append(ids, url.ID)
+		if url.DomainID != domainID {
+			shortCodes = append(shortCodes, url.ShortCode)
+		}
+	}
+	if len(shortCodes) == 0 {
+		return nil
+	}
+
+	taken, err := queries.GetTakenShortCodes(domainID, shortCodes, ids)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(urls)+len(taken))
+	for _, shortCode := range taken {
+		used[shortCode] = true
+	}
+
+	// URLs already on the domain keep their short code
+	for _, url := range urls {
+		if url.DomainID == domainID {
+			used[url.ShortCode] = true
+		}
+	}
+
+	for _, url := range urls {
+		if url.DomainID == domainID {
+			continue
+		}
+		if used[url.ShortCode] {
+			setBulkFailure(results, url, "short code already in use on the target domain")
+			continue
+		}
+		used[url.ShortCode] = true
+	}
+
+	return nil
+}
+
+// setBulkFailure marks the result of a URL as failed
+func setBulkFailure(results []BulkURLResult, url models.URL, errMsg string) {
+	for i := range results {
+		if results[i].URLID == url.ID {
+			results[i] = BulkURLResult{URLID: url.ID, ShortCode: url.ShortCode, Status: bulkStatusFailed, Error: errMsg}
+			return
+		}
+	}
+}
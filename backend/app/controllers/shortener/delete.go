@@ -2,13 +2,14 @@ package shortener
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yorukot/zipt/app/queries"
 	"github.com/yorukot/zipt/pkg/utils"
 )
 
-// DeleteURL handles the deletion of a shortened URL
+// DeleteURL moves a shortened URL to the workspace trash, it stops redirecting right away
 // This endpoint requires authentication and only allows users to delete their own URLs
 func DeleteURL(c *gin.Context) {
 	// Get user ID from context (set by middleware)
@@ -56,12 +57,14 @@ func DeleteURL(c *gin.Context) {
 	// Workspace permission is already verified by middleware.CheckWorkspaceRoleAndStore()
 	// The middleware ensures the user has appropriate permissions for the workspace
 
-	// Delete the URL
+	// Move the URL to the trash
 	result = queries.DeleteURLQueueByID(url.ID)
 	if result.Error != nil {
 		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting URL", utils.ErrSaveData, result.Error)
 		return
 	}
 
-	utils.FullyResponse(c, http.StatusOK, "URL deleted successfully", nil, nil)
+	utils.FullyResponse(c, http.StatusOK, "URL moved to trash", nil, gin.H{
+		"purges_at": time.Now().Add(getTrashRetention()),
+	})
 }
@@ -0,0 +1,276 @@
+package shortener
+
+import (
+	"math/rand/v2"
+	neturl "net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mileusna/useragent"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/deeplink"
+	"github.com/yorukot/zipt/pkg/geoip"
+	"github.com/yorukot/zipt/pkg/language"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/schedule"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// stickyVariantDuration is how long a visitor keeps the variant of a sticky URL
+const stickyVariantDuration = 30 * 24 * time.Hour
+
+// clickDestination is where a click gets redirected to and which rule picked it
+type clickDestination struct {
+	URL      string
+	GeoRule  string
+	Variant  string
+	Language string
+	Source   string // How the visitor reached the short URL, a direct click or a QR code scan
+
+	// DeepLink is set when the app has to be opened from an intermediate page,
+	// URL is then used as the fallback when the app is not installed
+	DeepLink string
+}
+
+// resolveDestination picks the destination of a click, falling back to the original URL
+func resolveDestination(c *gin.Context, url models.URL) clickDestination {
+	destination := clickDestination{
+		URL:      url.OriginalURL,
+		GeoRule:  models.GeoRuleFallback,
+		Variant:  models.VariantNone,
+		Language: models.LanguageDefault,
+	}
+
+	if variant := pickVariant(c, url); variant != nil {
+		destination.URL = variant.DestinationURL
+		destination.Variant = utils.Uint64ToStr(variant.ID)
+	}
+
+	// Schedule rules override the rotated variant
+	if rule := matchScheduleRule(url); rule != nil {
+		destination.URL = rule.DestinationURL
+		destination.Variant = models.VariantNone
+	}
+
+	// Language rules override the time window
+	if rule := matchLanguageRule(c, url); rule != nil {
+		destination.URL = rule.DestinationURL
+		destination.Language = rule.Language
+		destination.Variant = models.VariantNone
+	}
+
+	// Geo rules override all of the above
+	if country, _ := geoip.Lookup(c.ClientIP()); country != "" {
+		rule, result := queries.GetGeoRuleByCountry(url.ID, country)
+		if result.Error != nil {
+			logger.Log.Sugar().Errorf("Failed to get geo rule: %v", result.Error)
+		} else if rule.ID != 0 {
+			destination.URL = rule.DestinationURL
+			destination.GeoRule = rule.CountryCode
+			destination.Variant = models.VariantNone
+		}
+	}
+
+	applyPlatformDestination(c, url, &destination)
+
+	return destination
+}
+
+// scheduleEvaluator matches schedule rules against the current time
+var scheduleEvaluator = schedule.NewEvaluator(time.Now)
+
+// matchScheduleRule returns the first schedule rule of the URL whose window contains the current time
+func matchScheduleRule(url models.URL) *models.URLScheduleRule {
+	rules, result := queries.GetScheduleRulesByURLID(url.ID)
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to get schedule rules: %v", result.Error)
+		return nil
+	}
+
+	windows := make([]schedule.Window, 0, len(rules))
+	matchable := make([]int, 0, len(rules))
+	for i, rule := range rules {
+		window, err := schedule.ParseWindow(rule.Days, rule.StartTime, rule.EndTime, rule.Timezone)
+		if err != nil {
+			logger.Log.Sugar().Warnf("Skipping invalid schedule rule %d: %v", rule.ID, err)
+			continue
+		}
+		windows = append(windows, window)
+		matchable = append(matchable, i)
+	}
+
+	if match := scheduleEvaluator.Match(windows); match >= 0 {
+		return &rules[matchable[match]]
+	}
+	return nil
+}
+
+// matchLanguageRule negotiates the Accept-Language header of the visitor against the language rules of the URL
+func matchLanguageRule(c *gin.Context, url models.URL) *models.URLLanguageRule {
+	header := c.GetHeader("Accept-Language")
+	if header == "" {
+		return nil
+	}
+
+	rules, result := queries.GetLanguageRulesByURLID(url.ID)
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to get language rules: %v", result.Error)
+		return nil
+	}
+
+	languages := make([]string, len(rules))
+	for i, rule := range rules {
+		languages[i] = rule.Language
+	}
+
+	negotiated := language.Negotiate(header, languages)
+	for i := range rules {
+		if negotiated != "" && rules[i].Language == negotiated {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// pickVariant picks a weighted variant of the URL, returning visitors keep their variant when the URL is sticky
+func pickVariant(c *gin.Context, url models.URL) *models.URLVariant {
+	variants, result := queries.GetVariantsByURLID(url.ID)
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to get variants: %v", result.Error)
+		return nil
+	}
+
+	totalWeight := 0
+	for _, variant := range variants {
+		totalWeight += variant.Weight
+	}
+	if totalWeight <= 0 {
+		return nil
+	}
+
+	cookieName := "zipt_variant_" + utils.Uint64ToStr(url.ID)
+	if url.StickyVariants {
+		if cookie, err := c.Cookie(cookieName); err == nil {
+			for i := range variants {
+				if utils.Uint64ToStr(variants[i].ID) == cookie && variants[i].Weight > 0 {
+					return &variants[i]
+				}
+			}
+		}
+	}
+
+	picked := &variants[len(variants)-1]
+	target := rand.IntN(totalWeight)
+	for i := range variants {
+		if target < variants[i].Weight {
+			picked = &variants[i]
+			break
+		}
+		target -= variants[i].Weight
+	}
+
+	if url.StickyVariants {
+		c.SetCookie(cookieName, utils.Uint64ToStr(picked.ID), int(stickyVariantDuration.Seconds()), "/", "", utils.IsCookieSecure(), true)
+	}
+
+	return picked
+}
+
+// applyPlatformDestination sends iOS and Android visitors to the app deep link or store fallback of the URL
+func applyPlatformDestination(c *gin.Context, url models.URL, destination *clickDestination) {
+	ua := useragent.Parse(c.Request.UserAgent())
+
+	var deepLink, fallbackURL string
+	switch {
+	case ua.IsIOS():
+		deepLink, fallbackURL = url.IOSDeepLink, url.IOSFallbackURL
+	case ua.IsAndroid():
+		deepLink, fallbackURL = url.AndroidDeepLink, url.AndroidFallbackURL
+	default:
+		return
+	}
+
+	if fallbackURL != "" {
+		destination.URL = fallbackURL
+	}
+
+	// Links stored before their scheme was checked must not run script on the short domain
+	if deepLink == "" || !deeplink.Valid(deepLink) {
+		return
+	}
+
+	// Universal links and app links are opened by the OS itself, so a plain redirect is enough
+	if isValidHTTPURL(deepLink) {
+		destination.URL = deepLink
+		return
+	}
+
+	destination.DeepLink = deepLink
+}
+
+// composeDestinationURL adds the UTM parameters of the URL to the destination, then appends the
+// forwarded path and merges the query string of the visitor depending on the URL settings
+func composeDestinationURL(url models.URL, destination string, path string, query neturl.Values) string {
+	utm := map[string]string{
+		"utm_source":   url.UTMSource,
+		"utm_medium":   url.UTMMedium,
+		"utm_campaign": url.UTMCampaign,
+		"utm_term":     url.UTMTerm,
+		"utm_content":  url.UTMContent,
+	}
+	hasUTM := false
+	for _, value := range utm {
+		if value != "" {
+			hasUTM = true
+			break
+		}
+	}
+
+	mode := url.QueryPassthrough
+	if mode == "" || mode == models.QueryPassthroughOff {
+		mode = models.QueryPassthroughOff
+		// Path forwarding always carried the query string of the visitor along
+		if url.ForwardPath {
+			mode = models.QueryPassthroughReplace
+		}
+	}
+	if mode == models.QueryPassthroughOff {
+		query = nil
+	}
+
+	// Leave the destination untouched when there is nothing to compose
+	if !hasUTM && path == "" && len(query) == 0 {
+		return destination
+	}
+
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		logger.Log.Sugar().Warnf("Failed to parse destination %q: %v", destination, err)
+		return destination
+	}
+
+	if path != "" {
+		parsed = parsed.JoinPath(path)
+	}
+
+	merged := parsed.Query()
+	for key, value := range utm {
+		if value != "" {
+			merged.Set(key, value)
+		}
+	}
+
+	for key, values := range query {
+		_, exists := merged[key]
+		switch {
+		case !exists, mode == models.QueryPassthroughReplace:
+			merged[key] = values
+		case mode == models.QueryPassthroughAppend:
+			merged[key] = append(merged[key], values...)
+		}
+	}
+
+	parsed.RawQuery = merged.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,263 @@
+package shortener
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/csvcell"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// Export formats
+const (
+	exportFormatCSV    = "csv"
+	exportFormatJSON   = "json"
+	exportFormatNDJSON = "ndjson"
+)
+
+// exportBatchSize is the number of URLs loaded from the database at once while exporting
+const exportBatchSize = 500
+
+// exportContentTypes maps each export format to its content type
+var exportContentTypes = map[string]string{
+	exportFormatCSV:    "text/csv; charset=utf-8",
+	exportFormatJSON:   "application/json; charset=utf-8",
+	exportFormatNDJSON: "application/x-ndjson; charset=utf-8",
+}
+
+// exportCSVHeader is the header line of CSV exports, in the order of ExportedURL.csvRecord
+var exportCSVHeader = []string{
+	"id", "short_code", "short_url", "domain", "original_url", "title",
+	"expires_at", "total_clicks", "tags", "created_at", "updated_at",
+}
+
+// ExportedURL is a single URL of a workspace export
+type ExportedURL struct {
+	ID          uint64     `json:"id,string"`
+	ShortCode   string     `json:"short_code"`
+	ShortURL    string     `json:"short_url"`
+	Domain      string     `json:"domain"`
+	OriginalURL string     `json:"original_url"`
+	Title       string     `json:"title"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	TotalClicks int64      `json:"total_clicks"`
+	Tags        []string   `json:"tags"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// csvRecord returns the exported URL as a CSV line, tags are separated by | like in imports.
+// Cells spreadsheet apps would run as a formula are escaped.
+func (url ExportedURL) csvRecord() []string {
+	var expiresAt string
+	if url.ExpiresAt != nil {
+		expiresAt = url.ExpiresAt.Format(time.RFC3339)
+	}
+
+	record := []string{
+		utils.Uint64ToStr(url.ID),
+		url.ShortCode,
+		url.ShortURL,
+		url.Domain,
+		url.OriginalURL,
+		url.Title,
+		expiresAt,
+		strconv.FormatInt(url.TotalClicks, 10),
+		strings.Join(url.Tags, "|"),
+		url.CreatedAt.Format(time.RFC3339),
+		url.UpdatedAt.Format(time.RFC3339),
+	}
+	for i, cell := range record {
+		record[i] = csvcell.Escape(cell)
+	}
+	return record
+}
+
+// ExportURLs streams every URL of the workspace matching the list filters as CSV, JSON or NDJSON
+func ExportURLs(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	format := c.DefaultQuery("format", exportFormatCSV)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		utils.FullyResponse(c, http.StatusBadRequest, "format must be one of csv, json or ndjson", utils.ErrBadRequest, nil)
+		return
+	}
+
+	filter, err := getURLFilter(c)
+	if err != nil {
+		return
+	}
+
+	// The default domain is not preloaded, it is loaded once when needed
+	var defaultDomain *models.Domain
+	domainName := func(url models.URL) string {
+		domain := url.Domain
+		if domain == nil && url.DomainID == 0 {
+			if defaultDomain == nil {
+				defaultDomain, _ = queries.GetDomainByID(0)
+				if defaultDomain == nil {
+					defaultDomain = &models.Domain{}
+				}
+			}
+			domain = defaultDomain
+		}
+		if domain == nil || !domain.Verified {
+			return ""
+		}
+		return domain.Domain
+	}
+
+	writer := newURLExportWriter(c, format)
+	started := false
+
+	// Headers are only sent once the first batch loaded, so a failing query still gets an error response
+	start := func() error {
+		started = true
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="zipt-urls-%s.%s"`, time.Now().Format("20060102-150405"), format))
+		c.Status(http.StatusOK)
+		return writer.begin()
+	}
+
+	err = queries.GetURLsInBatchesQueue(workspaceID.(uint64), filter, exportBatchSize, func(urls []models.URL) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		for _, url := range urls {
+			name := domainName(url)
+			normalizedDomain, _ := utils.NormalizeDomainName(name)
+
+			tags := make([]string, 0, len(url.Tags))
+			for _, tag := range url.Tags {
+				tags = append(tags, tag.Name)
+			}
+
+			exported := ExportedURL{
+				ID:          url.ID,
+				ShortCode:   url.ShortCode,
+				ShortURL:    utils.GetFullShortURL(name, url.ShortCode),
+				Domain:      normalizedDomain,
+				OriginalURL: url.OriginalURL,
+				Title:       url.Title,
+				ExpiresAt:   url.ExpiresAt,
+				TotalClicks: url.TotalClicks,
+				Tags:        tags,
+				CreatedAt:   url.CreatedAt,
+				UpdatedAt:   url.UpdatedAt,
+			}
+			if err := writer.write(exported); err != nil {
+				return err
+			}
+		}
+
+		return writer.flush()
+	})
+
+	if err != nil {
+		if !started {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error exporting URLs", utils.ErrGetData, err)
+			return
+		}
+		// The response is already on its way, the client sees a truncated file
+		logger.Log.Sugar().Errorf("Failed to export URLs of workspace %d: %v", workspaceID.(uint64), err)
+		return
+	}
+
+	// An export without any URL still gets its headers and an empty document
+	if !started {
+		err = start()
+	}
+	if err == nil {
+		err = writer.end()
+	}
+	if err != nil {
+		logger.Log.Sugar().Errorf("Failed to export URLs of workspace %d: %v", workspaceID.(uint64), err)
+	}
+}
+
+// urlExportWriter writes exported URLs to the response one at a time in the requested format
+type urlExportWriter struct {
+	c      *gin.Context
+	format string
+	csv    *csv.Writer
+	count  int
+}
+
+// newURLExportWriter creates an export writer for the response
+func newURLExportWriter(c *gin.Context, format string) *urlExportWriter {
+	return &urlExportWriter{c: c, format: format, csv: csv.NewWriter(c.Writer)}
+}
+
+// begin writes what comes before the first URL
+func (writer *urlExportWriter) begin() error {
+	switch writer.format {
+	case exportFormatCSV:
+		return writer.csv.Write(exportCSVHeader)
+	case exportFormatJSON:
+		_, err := writer.c.Writer.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+// write writes a single URL
+func (writer *urlExportWriter) write(url ExportedURL) error {
+	defer func() { writer.count++ }()
+
+	if writer.format == exportFormatCSV {
+		return writer.csv.Write(url.csvRecord())
+	}
+
+	data, err := json.Marshal(url)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case writer.format == exportFormatNDJSON:
+		data = append(data, '\n')
+	case writer.count > 0:
+		data = append([]byte(","), data...)
+	}
+	_, err = writer.c.Writer.Write(data)
+	return err
+}
+
+// flush sends what was written so far to the client
+func (writer *urlExportWriter) flush() error {
+	if writer.format == exportFormatCSV {
+		writer.csv.Flush()
+		if err := writer.csv.Error(); err != nil {
+			return err
+		}
+	}
+	writer.c.Writer.Flush()
+	return nil
+}
+
+// end writes what comes after the last URL
+func (writer *urlExportWriter) end() error {
+	if writer.format == exportFormatJSON {
+		if _, err := writer.c.Writer.WriteString("]"); err != nil {
+			return err
+		}
+	}
+	return writer.flush()
+}
@@ -0,0 +1,87 @@
+package shortener
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// fallbackPageData is the data rendered into fallback pages, including custom templates
+type fallbackPageData struct {
+	ShortCode  string
+	Kind       string
+	Message    string
+	StatusCode int
+}
+
+// respondUnavailable answers a click on a short URL that can't be followed. API clients get the
+// usual JSON error, browsers get the fallback page of the domain or workspace, or the default page.
+func respondUnavailable(c *gin.Context, kind string, workspaceID *uint64, domainID uint64, statusCode int, message string, errorCode string, data interface{}) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		utils.FullyResponse(c, statusCode, message, errorCode, data)
+		return
+	}
+
+	pageData := fallbackPageData{
+		ShortCode:  c.Param("shortCode"),
+		Kind:       kind,
+		Message:    message,
+		StatusCode: statusCode,
+	}
+
+	if workspaceID != nil {
+		page, result := queries.FindFallbackPage(*workspaceID, domainID, kind)
+		switch {
+		case result.Error != nil:
+			logger.Log.Sugar().Errorf("Failed to get fallback page: %v", result.Error)
+		case page.RedirectURL != "":
+			redirectTo(c, models.RedirectTypeFound, page.RedirectURL)
+			return
+		case page.Template != "" && isWorkspaceDomain(*workspaceID, domainID):
+			if renderFallbackTemplate(c, page, pageData) {
+				return
+			}
+		}
+	}
+
+	renderPage(c, statusCode, "fallback.html", pageData)
+}
+
+// isWorkspaceDomain reports whether the domain is a verified domain of the workspace. Custom templates are only
+// served there: on the default or a shared domain, the HTML and scripts of one workspace would run on the
+// origin of every other short URL.
+func isWorkspaceDomain(workspaceID uint64, domainID uint64) bool {
+	if domainID == 0 {
+		return false
+	}
+	domain, result := queries.GetDomainByID(domainID)
+	if result.Error != nil || domain == nil {
+		return false
+	}
+	return domain.Verified && domain.WorkspaceID != nil && *domain.WorkspaceID == workspaceID
+}
+
+// renderFallbackTemplate renders the custom template of a fallback page, it returns false
+// without writing anything when the template fails so the default page can be used instead
+func renderFallbackTemplate(c *gin.Context, page models.FallbackPage, data fallbackPageData) bool {
+	tmpl, err := template.New(page.Kind).Parse(page.Template)
+	if err != nil {
+		logger.Log.Sugar().Warnf("Failed to parse fallback page %d: %v", page.ID, err)
+		return false
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		logger.Log.Sugar().Warnf("Failed to render fallback page %d: %v", page.ID, err)
+		return false
+	}
+
+	c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	c.Data(data.StatusCode, "text/html; charset=utf-8", body.Bytes())
+	return true
+}
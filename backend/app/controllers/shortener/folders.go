@@ -0,0 +1,217 @@
+package shortener
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// FolderRequest represents the request body for creating or updating a folder
+type FolderRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	ParentID string `json:"parent_id" binding:"omitempty"` // Empty for a top level folder
+}
+
+// GetFolders returns all folders of the workspace, the hierarchy is built from their parent IDs
+func GetFolders(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	folders, result := queries.GetFoldersByWorkspaceID(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving folders", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Folders retrieved successfully", nil, folders)
+}
+
+// CreateFolder adds a folder to the workspace
+func CreateFolder(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	var request FolderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Folder name is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	parentID, err := getRequestFolder(c, request.ParentID)
+	if err != nil {
+		return
+	}
+
+	folder := models.Folder{
+		ID:          encryption.GenerateID(),
+		WorkspaceID: workspaceID.(uint64),
+		ParentID:    parentID,
+		Name:        name,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if result := queries.CreateFolderQueue(folder); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating folder", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Folder created successfully", nil, folder)
+}
+
+// UpdateFolder renames a folder or moves it under another parent
+func UpdateFolder(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	folderID, err := utils.StrToUint64(c.Param("folderID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid folder ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	folder, result := queries.GetFolderByID(workspaceID.(uint64), folderID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Folder not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request FolderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Folder name is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	parentID, err := getRequestFolder(c, request.ParentID)
+	if err != nil {
+		return
+	}
+
+	// A folder can't be moved into itself or one of its subfolders
+	if parentID != nil {
+		isDescendant, err := isFolderDescendant(folder.WorkspaceID, *parentID, folder.ID)
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking folder hierarchy", utils.ErrGetData, err)
+			return
+		}
+		if isDescendant {
+			utils.FullyResponse(c, http.StatusBadRequest, "A folder can't be moved into itself or one of its subfolders", utils.ErrBadRequest, nil)
+			return
+		}
+	}
+
+	folder.Name = name
+	folder.ParentID = parentID
+
+	if result := queries.UpdateFolderQueue(folder); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating folder", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Folder updated successfully", nil, folder)
+}
+
+// DeleteFolder removes a folder, its subfolders move up to its parent and its URLs are kept outside any folder
+func DeleteFolder(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	folderID, err := utils.StrToUint64(c.Param("folderID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid folder ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	folder, result := queries.GetFolderByID(workspaceID.(uint64), folderID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Folder not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	if err := queries.DeleteFolderQueue(folder); err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting folder", utils.ErrDeleteData, err)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Folder deleted successfully", nil, nil)
+}
+
+// isFolderDescendant reports whether folderID is ancestorID itself or one of its subfolders
+func isFolderDescendant(workspaceID, folderID, ancestorID uint64) (bool, error) {
+	visited := map[uint64]bool{}
+	for current := &folderID; current != nil; {
+		if *current == ancestorID {
+			return true, nil
+		}
+		if visited[*current] {
+			return false, nil
+		}
+		visited[*current] = true
+
+		folder, result := queries.GetFolderByID(workspaceID, *current)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		current = folder.ParentID
+	}
+	return false, nil
+}
+
+// getRequestFolder loads the workspace folder referenced by a request, an empty ID means no folder.
+// The error response has already been sent when an error is returned.
+func getRequestFolder(c *gin.Context, folderID string) (*uint64, error) {
+	if folderID == "" {
+		return nil, nil
+	}
+
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		errMsg := "folders can only be used on workspace URLs"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
+	id, err := utils.StrToUint64(folderID)
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid folder ID", utils.ErrBadRequest, nil)
+		return nil, err
+	}
+
+	folder, result := queries.GetFolderByID(workspaceID.(uint64), id)
+	if result.Error != nil {
+		errMsg := "folder not found in this workspace"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
+	return &folder.ID, nil
+}
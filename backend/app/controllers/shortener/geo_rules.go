@@ -0,0 +1,158 @@
+package shortener
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// GeoRuleRequest represents the request body for creating or updating a geo rule
+type GeoRuleRequest struct {
+	CountryCode    string `json:"country_code" binding:"required,len=2,alpha"`
+	DestinationURL string `json:"destination_url" binding:"required,url"`
+}
+
+// GetGeoRules returns all geo rules of a URL
+func GetGeoRules(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	rules, result := queries.GetGeoRulesByURLID(url.ID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving geo rules", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Geo rules retrieved successfully", nil, rules)
+}
+
+// CreateGeoRule adds a country based destination to a URL
+func CreateGeoRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	var request GeoRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	countryCode := strings.ToUpper(request.CountryCode)
+
+	exists, err := queries.CheckGeoRuleExists(url.ID, countryCode)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking geo rule", utils.ErrGetData, err)
+		return
+	}
+	if exists {
+		utils.FullyResponse(c, http.StatusConflict, "A geo rule for this country already exists", utils.ErrResourceExists, nil)
+		return
+	}
+
+	rule := models.URLGeoRule{
+		ID:             encryption.GenerateID(),
+		URLID:          url.ID,
+		CountryCode:    countryCode,
+		DestinationURL: request.DestinationURL,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if result := queries.CreateGeoRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating geo rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Geo rule created successfully", nil, rule)
+}
+
+// UpdateGeoRule updates the country or destination of a geo rule
+func UpdateGeoRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid geo rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	rule, result := queries.GetGeoRuleByID(url.ID, ruleID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Geo rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request GeoRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	countryCode := strings.ToUpper(request.CountryCode)
+
+	// Changing the country must not collide with another rule
+	if countryCode != rule.CountryCode {
+		exists, err := queries.CheckGeoRuleExists(url.ID, countryCode)
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking geo rule", utils.ErrGetData, err)
+			return
+		}
+		if exists {
+			utils.FullyResponse(c, http.StatusConflict, "A geo rule for this country already exists", utils.ErrResourceExists, nil)
+			return
+		}
+	}
+
+	rule.CountryCode = countryCode
+	rule.DestinationURL = request.DestinationURL
+
+	if result := queries.UpdateGeoRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating geo rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Geo rule updated successfully", nil, rule)
+}
+
+// DeleteGeoRule removes a geo rule from a URL
+func DeleteGeoRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid geo rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteGeoRuleQueue(url.ID, ruleID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting geo rule", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Geo rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Geo rule deleted successfully", nil, nil)
+}
@@ -1,15 +1,36 @@
 package shortener
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
 	"github.com/yorukot/zipt/app/queries"
 	"github.com/yorukot/zipt/pkg/utils"
 )
 
-// GetUserURLs returns all URLs created by the authenticated user
+// URL list page sizes
+const (
+	defaultURLListLimit = 50
+	maxURLListLimit     = 100
+)
+
+// urlListCursor is the opaque cursor handed to clients, it remembers the sort order
+// so a cursor can't be reused with a different one
+type urlListCursor struct {
+	queries.URLCursor
+	SortBy    string `json:"s"`
+	Ascending bool   `json:"a,omitempty"`
+}
+
+// GetUserURLs returns one page of the workspace URLs matching the search and filters
 func GetUserURLs(c *gin.Context) {
 	// This endpoint should be protected by authentication middleware
 	_, exists := c.Get("userID")
@@ -24,8 +45,18 @@ func GetUserURLs(c *gin.Context) {
 		return
 	}
 
-	// Get workspace's URLs with domain information preloaded
-	urls, err := queries.GetURLsByWorkspaceID(workspaceID.(uint64))
+	filter, err := getURLFilter(c)
+	if err != nil {
+		return
+	}
+
+	page, err := getURLPage(c)
+	if err != nil {
+		return
+	}
+
+	// Get one page of the workspace's URLs with domain information preloaded
+	urls, total, hasMore, err := queries.GetURLsPageByWorkspaceQueue(workspaceID.(uint64), filter, page)
 	if err != nil {
 		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving URLs", utils.ErrGetData, err)
 		return
@@ -33,19 +64,46 @@ func GetUserURLs(c *gin.Context) {
 
 	// Enhance URL data with formatted short URLs
 	type EnhancedURL struct {
-		ID          uint64     `json:"id,string"`
-		ShortCode   string     `json:"short_code"`
-		OriginalURL string     `json:"original_url"`
-		ShortURL    string     `json:"short_url"`
-		DomainID    uint64     `json:"domain_id,omitempty"`
-		DomainName  string     `json:"domain_name,omitempty"`
-		ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-		CreatedAt   time.Time  `json:"created_at"`
-		UpdatedAt   time.Time  `json:"updated_at"`
-		TotalClicks int64      `json:"total_clicks"`
+		ID                 uint64       `json:"id,string"`
+		ShortCode          string       `json:"short_code"`
+		OriginalURL        string       `json:"original_url"`
+		Title              string       `json:"title,omitempty"`
+		ShortURL           string       `json:"short_url"`
+		DomainID           uint64       `json:"domain_id,omitempty"`
+		DomainName         string       `json:"domain_name,omitempty"`
+		RedirectType       int          `json:"redirect_type"`
+		PasswordProtected  bool         `json:"password_protected"`
+		ExpiresAt          *time.Time   `json:"expires_at,omitempty"`
+		MaxClicks          *int64       `json:"max_clicks,omitempty"`
+		ActivatesAt        *time.Time   `json:"activates_at,omitempty"`
+		PrelaunchURL       string       `json:"prelaunch_url,omitempty"`
+		Status             string       `json:"status"`
+		FolderID           *uint64      `json:"folder_id,string,omitempty"`
+		Tags               []models.Tag `json:"tags"`
+		StickyVariants     bool         `json:"sticky_variants"`
+		ForwardPath        bool         `json:"forward_path"`
+		QueryPassthrough   string       `json:"query_passthrough"`
+		UTMSource          string       `json:"utm_source,omitempty"`
+		UTMMedium          string       `json:"utm_medium,omitempty"`
+		UTMCampaign        string       `json:"utm_campaign,omitempty"`
+		UTMTerm            string       `json:"utm_term,omitempty"`
+		UTMContent         string       `json:"utm_content,omitempty"`
+		IOSDeepLink        string       `json:"ios_deep_link,omitempty"`
+		IOSFallbackURL     string       `json:"ios_fallback_url,omitempty"`
+		AndroidDeepLink    string       `json:"android_deep_link,omitempty"`
+		AndroidFallbackURL string       `json:"android_fallback_url,omitempty"`
+		OGTitle            string       `json:"og_title,omitempty"`
+		OGDescription      string       `json:"og_description,omitempty"`
+		OGImageURL         string       `json:"og_image_url,omitempty"`
+		BlockedReason      string       `json:"blocked_reason,omitempty"`
+		Health             URLHealth    `json:"health"`
+		CreatedAt          time.Time    `json:"created_at"`
+		UpdatedAt          time.Time    `json:"updated_at"`
+		TotalClicks        int64        `json:"total_clicks"`
 	}
 
 	enhancedURLs := make([]EnhancedURL, 0, len(urls))
+	now := time.Now()
 
 	for _, url := range urls {
 		var domainName string
@@ -60,19 +118,221 @@ func GetUserURLs(c *gin.Context) {
 		normalizedDomain, _ := utils.NormalizeDomainName(domainName)
 
 		enhancedURLs = append(enhancedURLs, EnhancedURL{
-			ID:          url.ID,
-			ShortCode:   url.ShortCode,
-			OriginalURL: url.OriginalURL,
-			ShortURL:    shortURL,
-			DomainID:    url.DomainID,
-			DomainName:  normalizedDomain,
-			ExpiresAt:   url.ExpiresAt,
-			CreatedAt:   url.CreatedAt,
-			UpdatedAt:   url.UpdatedAt,
-			TotalClicks: url.TotalClicks,
+			ID:                 url.ID,
+			ShortCode:          url.ShortCode,
+			OriginalURL:        url.OriginalURL,
+			Title:              url.Title,
+			ShortURL:           shortURL,
+			DomainID:           url.DomainID,
+			DomainName:         normalizedDomain,
+			RedirectType:       url.RedirectType,
+			PasswordProtected:  url.Password != "",
+			ExpiresAt:          url.ExpiresAt,
+			MaxClicks:          url.MaxClicks,
+			ActivatesAt:        url.ActivatesAt,
+			PrelaunchURL:       url.PrelaunchURL,
+			Status:             getURLStatus(url, now),
+			FolderID:           url.FolderID,
+			Tags:               url.Tags,
+			StickyVariants:     url.StickyVariants,
+			ForwardPath:        url.ForwardPath,
+			QueryPassthrough:   url.QueryPassthrough,
+			UTMSource:          url.UTMSource,
+			UTMMedium:          url.UTMMedium,
+			UTMCampaign:        url.UTMCampaign,
+			UTMTerm:            url.UTMTerm,
+			UTMContent:         url.UTMContent,
+			IOSDeepLink:        url.IOSDeepLink,
+			IOSFallbackURL:     url.IOSFallbackURL,
+			AndroidDeepLink:    url.AndroidDeepLink,
+			AndroidFallbackURL: url.AndroidFallbackURL,
+			OGTitle:            url.OGTitle,
+			OGDescription:      url.OGDescription,
+			OGImageURL:         url.OGImageURL,
+			BlockedReason:      url.BlockedReason,
+			Health:             getURLHealth(url),
+			CreatedAt:          url.CreatedAt,
+			UpdatedAt:          url.UpdatedAt,
+			TotalClicks:        url.TotalClicks,
+		})
+	}
+
+	// The cursor of the last URL fetches the next page
+	var nextCursor string
+	if hasMore {
+		nextCursor = encodeURLListCursor(urlListCursor{
+			URLCursor: queries.NewURLCursor(urls[len(urls)-1], page.SortBy),
+			SortBy:    page.SortBy,
+			Ascending: page.Ascending,
 		})
 	}
 
 	// Return workspace's URLs with enhanced information
-	c.JSON(http.StatusOK, enhancedURLs)
+	utils.FullyResponse(c, http.StatusOK, "URLs retrieved successfully", nil, gin.H{
+		"urls":        enhancedURLs,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
+
+// getURLFilter parses the filter query parameters of the URL list.
+// tag_id can be repeated to require several tags, folder_id=none lists URLs outside of any folder.
+func getURLFilter(c *gin.Context) (queries.URLFilter, error) {
+	filter := queries.URLFilter{Search: strings.TrimSpace(c.Query("q"))}
+
+	seen := map[uint64]bool{}
+	for _, tagID := range c.QueryArray("tag_id") {
+		id, err := utils.StrToUint64(tagID)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid tag ID", utils.ErrBadRequest, tagID)
+			return filter, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			filter.TagIDs = append(filter.TagIDs, id)
+		}
+	}
+
+	switch folderID := c.Query("folder_id"); folderID {
+	case "":
+	case "none":
+		filter.Unfiled = true
+	default:
+		id, err := utils.StrToUint64(folderID)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid folder ID", utils.ErrBadRequest, folderID)
+			return filter, err
+		}
+		filter.FolderID = &id
+	}
+
+	if domainID := c.Query("domain_id"); domainID != "" {
+		id, err := utils.StrToUint64(domainID)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid domain ID", utils.ErrBadRequest, domainID)
+			return filter, err
+		}
+		filter.DomainID = &id
+	}
+
+	var err error
+	if filter.CreatedAfter, err = getTimeQuery(c, "created_after"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = getTimeQuery(c, "created_before"); err != nil {
+		return filter, err
+	}
+
+	switch filter.Expiry = c.Query("expiry"); filter.Expiry {
+	case "", queries.URLExpiryActive, queries.URLExpiryExpired, queries.URLExpiryNever:
+	default:
+		errMsg := "expiry must be one of active, expired or never"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return filter, errors.New(errMsg)
+	}
+
+	if filter.MinClicks, err = getClicksQuery(c, "min_clicks"); err != nil {
+		return filter, err
+	}
+	if filter.MaxClicks, err = getClicksQuery(c, "max_clicks"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// getURLPage parses the sort, order, limit and cursor query parameters of the URL list
+func getURLPage(c *gin.Context) (queries.URLPage, error) {
+	page := queries.URLPage{SortBy: c.DefaultQuery("sort", queries.URLSortCreatedAt), Limit: defaultURLListLimit}
+
+	switch page.SortBy {
+	case queries.URLSortCreatedAt, queries.URLSortUpdatedAt, queries.URLSortClicks:
+	default:
+		errMsg := "sort must be one of created_at, updated_at or clicks"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return page, errors.New(errMsg)
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		page.Ascending = true
+	case "desc":
+	default:
+		errMsg := "order must be asc or desc"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return page, errors.New(errMsg)
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxURLListLimit {
+			errMsg := fmt.Sprintf("limit must be between 1 and %d", maxURLListLimit)
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return page, errors.New(errMsg)
+		}
+		page.Limit = value
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := decodeURLListCursor(cursor)
+		if err != nil || decoded.SortBy != page.SortBy || decoded.Ascending != page.Ascending {
+			errMsg := "invalid cursor for this sort order"
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return page, errors.New(errMsg)
+		}
+		page.After = &decoded.URLCursor
+	}
+
+	return page, nil
+}
+
+// getTimeQuery parses an optional RFC 3339 time or YYYY-MM-DD date query parameter
+func getTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if parsed, err = time.Parse(time.DateOnly, value); err != nil {
+			errMsg := name + " must be an RFC 3339 time or a YYYY-MM-DD date"
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return nil, errors.New(errMsg)
+		}
+	}
+	return &parsed, nil
+}
+
+// getClicksQuery parses an optional click count query parameter
+func getClicksQuery(c *gin.Context, name string) (*int64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	clicks, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || clicks < 0 {
+		errMsg := name + " must be a positive number"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+	return &clicks, nil
+}
+
+// encodeURLListCursor serializes a cursor into an opaque URL safe string
+func encodeURLListCursor(cursor urlListCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeURLListCursor parses a cursor created by encodeURLListCursor
+func decodeURLListCursor(value string) (urlListCursor, error) {
+	var cursor urlListCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
 }
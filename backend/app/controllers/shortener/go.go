@@ -1,14 +1,17 @@
 package shortener
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mileusna/useragent"
 	"github.com/yorukot/zipt/app/models"
 	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/crawler"
 	"github.com/yorukot/zipt/pkg/geoip"
 	"github.com/yorukot/zipt/pkg/logger"
 	"github.com/yorukot/zipt/pkg/utils"
@@ -23,9 +26,131 @@ func RedirectURL(c *gin.Context) {
 		return
 	}
 
+	// A trailing + shows the preview page instead of redirecting
+	if strings.HasSuffix(shortCode, "+") {
+		PreviewURL(c)
+		return
+	}
+
+	// A trailing .qr renders the QR code of the short URL
+	if strings.HasSuffix(shortCode, ".qr") {
+		QRCodeURL(c)
+		return
+	}
+
+	url, err := getURLByShortCode(c, shortCode)
+	if err != nil {
+		return
+	}
+
+	// Extra path segments are only accepted by URLs with path forwarding
+	forwardedPath := strings.TrimPrefix(c.Param("path"), "/")
+	if forwardedPath != "" && !url.ForwardPath {
+		respondUnavailable(c, models.FallbackNotFound, url.WorkspaceID, url.DomainID, http.StatusNotFound, "Short URL not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+	if hasDotSegment(forwardedPath) {
+		utils.FullyResponse(c, http.StatusBadRequest, "Forwarded path must not contain . or .. segments", utils.ErrBadRequest, nil)
+		return
+	}
+
+	// Blocked URLs never redirect, and their workspace fallback pages aren't used so they can't redirect either
+	if url.BlockedAt != nil {
+		respondUnavailable(c, models.FallbackInactive, nil, url.DomainID, http.StatusForbidden, "Short URL has been disabled because its destination is unsafe", utils.ErrResourceInactive, gin.H{
+			"reason": url.BlockedReason,
+		})
+		return
+	}
+
+	// Check if the URL has expired
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		respondUnavailable(c, models.FallbackExpired, url.WorkspaceID, url.DomainID, http.StatusGone, "Short URL has expired", utils.ErrResourceGone, nil)
+		return
+	}
+
+	// Check if the URL is not active yet, the prelaunch URL is never cached or tracked
+	if url.ActivatesAt != nil && url.ActivatesAt.After(time.Now()) {
+		if url.PrelaunchURL != "" {
+			redirectTo(c, models.RedirectTypeFound, url.PrelaunchURL)
+			return
+		}
+		respondUnavailable(c, models.FallbackInactive, url.WorkspaceID, url.DomainID, http.StatusForbidden, "Short URL is not active yet", utils.ErrResourceInactive, gin.H{
+			"activates_at": url.ActivatesAt,
+		})
+		return
+	}
+
+	// Check if the URL has reached its click limit
+	if url.MaxClicks != nil && url.TotalClicks >= *url.MaxClicks {
+		respondUnavailable(c, models.FallbackExpired, url.WorkspaceID, url.DomainID, http.StatusGone, "Short URL has reached its click limit", utils.ErrResourceGone, nil)
+		return
+	}
+
+	// Social crawlers unfurling the link get its card instead of the destination, card fetches are never
+	// counted as clicks. Anything that is redirected claims a click of the limit, the user agent can be forged,
+	// but redirected crawlers are not tracked in analytics.
+	decision := crawler.Decide(c.Request.UserAgent(), hasSocialCard(url), url.MaxClicks != nil)
+	if decision.SocialCard {
+		renderSocialCard(c, url)
+		return
+	}
+
+	// Password protected URLs are only tracked once they are unlocked
+	if !checkURLUnlocked(c, url) {
+		return
+	}
+
+	// Click limited URLs claim their click before redirecting, so two concurrent
+	// clicks can't both get the last one
+	clickClaimed := false
+	if decision.ClaimClick {
+		claimed, err := queries.ClaimURLClick(url.ID)
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking click limit", utils.ErrSaveData, err)
+			return
+		}
+		if !claimed {
+			respondUnavailable(c, models.FallbackExpired, url.WorkspaceID, url.DomainID, http.StatusGone, "Short URL has reached its click limit", utils.ErrResourceGone, nil)
+			return
+		}
+		clickClaimed = true
+	}
+
+	// Scans of the QR code carry a marker, which is not passed on to the destination
+	query := c.Request.URL.Query()
+	source := models.ClickSourceDirect
+	if query.Has(qrScanParam) {
+		source = models.ClickSourceQR
+		query.Del(qrScanParam)
+	}
+
+	// Pick the destination of this click
+	destination := resolveDestination(c, url)
+	destination.Source = source
+	destination.URL = composeDestinationURL(url, destination.URL, forwardedPath, query)
+
+	// Track analytics (async to not delay redirect)
+	if decision.Track {
+		go trackURLAnalytics(c, url.ID, !clickClaimed, destination)
+	}
+
+	// Custom scheme deep links need a page that tries the app before falling back
+	if destination.DeepLink != "" {
+		renderPage(c, http.StatusOK, "app.html", destination)
+		return
+	}
+
+	// Redirect to the destination
+	redirectTo(c, url.RedirectType, destination.URL)
+}
+
+// getURLByShortCode finds the URL of a short code on the requested host, custom domains
+// only resolve their own URLs. The error response has already been sent when an error is returned.
+func getURLByShortCode(c *gin.Context, shortCode string) (models.URL, error) {
 	// Check if we're on a custom domain
 	host := c.Request.Host
-	domainID := uint64(0) // Default to no domain
+	domainID := uint64(0)         // Default to no domain
+	var domainWorkspaceID *uint64 // Workspace of the custom domain, for its fallback pages
 
 	// If not on default domain, check if custom domain is registered
 	if host != utils.GetDefaultShortDomain() {
@@ -33,10 +158,11 @@ func RedirectURL(c *gin.Context) {
 		if result.Error == nil && domain.Verified {
 			// Use this domain's ID for the URL lookup
 			domainID = domain.ID
+			domainWorkspaceID = domain.WorkspaceID
 		}
 		if result.Error == nil && !domain.Verified {
 			utils.FullyResponse(c, http.StatusBadRequest, "Domain not verified", utils.ErrBadRequest, nil)
-			return
+			return models.URL{}, errors.New("domain not verified")
 		}
 	}
 
@@ -53,25 +179,35 @@ func RedirectURL(c *gin.Context) {
 	}
 
 	if result.Error != nil {
-		utils.FullyResponse(c, http.StatusNotFound, "Short URL not found", utils.ErrResourceNotFound, nil)
-		return
+		respondUnavailable(c, models.FallbackNotFound, domainWorkspaceID, domainID, http.StatusNotFound, "Short URL not found", utils.ErrResourceNotFound, nil)
+		return models.URL{}, result.Error
 	}
 
-	// Check if the URL has expired
-	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
-		utils.FullyResponse(c, http.StatusGone, "Short URL has expired", utils.ErrResourceGone, nil)
-		return
+	return url, nil
+}
+
+// redirectTo redirects with the link's redirect type, temporary redirects are
+// marked as non-cacheable so later clicks still reach the server
+func redirectTo(c *gin.Context, redirectType int, location string) {
+	if !models.IsValidRedirectType(redirectType) {
+		redirectType = models.DefaultRedirectType
 	}
 
-	// Track analytics (async to not delay redirect)
-	go trackURLAnalytics(c, url.ID)
+	// After the unlock form is posted, 307/308 would forward the password to the destination
+	if c.Request.Method == http.MethodPost {
+		redirectType = http.StatusSeeOther
+	}
+
+	if redirectType != models.RedirectTypeMovedPermanently && redirectType != models.RedirectTypePermanentRedirect {
+		c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	}
 
-	// Redirect to the original URL
-	c.Redirect(http.StatusMovedPermanently, url.OriginalURL)
+	c.Redirect(redirectType, location)
 }
 
-// trackURLAnalytics records analytics data for a URL click
-func trackURLAnalytics(c *gin.Context, urlID uint64) {
+// trackURLAnalytics records analytics data for a URL click,
+// countClick is false when the click was already counted by ClaimURLClick
+func trackURLAnalytics(c *gin.Context, urlID uint64, countClick bool, destination clickDestination) {
 	// Get referrer
 	referrer := c.Request.Referer()
 
@@ -108,7 +244,11 @@ func trackURLAnalytics(c *gin.Context, urlID uint64) {
 		Device:     device,
 		Browser:    browser,
 		OS:         os,
-	})
+		GeoRule:    destination.GeoRule,
+		Variant:    destination.Variant,
+		Language:   destination.Language,
+		Source:     destination.Source,
+	}, countClick)
 
 	if result != nil && result.Error != nil {
 		// Just log the error but don't affect the user experience
@@ -0,0 +1,89 @@
+package shortener
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// URLHealth is the health of the destination of a URL, as last checked by the link monitor
+type URLHealth struct {
+	Status        string     `json:"status"` // One of the models.URLHealth states
+	StatusCode    int        `json:"status_code,omitempty"`
+	LatencyMs     int64      `json:"latency_ms,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	Failures      int        `json:"consecutive_failures,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+}
+
+// BrokenURL is a URL of the workspace whose destination is broken
+type BrokenURL struct {
+	ID          uint64    `json:"id,string"`
+	ShortCode   string    `json:"short_code"`
+	OriginalURL string    `json:"original_url"`
+	Title       string    `json:"title,omitempty"`
+	ShortURL    string    `json:"short_url"`
+	DomainID    uint64    `json:"domain_id,omitempty"`
+	DomainName  string    `json:"domain_name,omitempty"`
+	TotalClicks int64     `json:"total_clicks"`
+	Health      URLHealth `json:"health"`
+}
+
+// getURLHealth returns the health of the current destination of the URL, the health of a previous destination is unknown
+func getURLHealth(url models.URL) URLHealth {
+	if url.Health == nil || url.Health.Destination != url.OriginalURL {
+		return URLHealth{Status: models.URLHealthUnknown}
+	}
+
+	checkedAt := url.Health.CheckedAt
+	return URLHealth{
+		Status:        url.Health.Status,
+		StatusCode:    url.Health.StatusCode,
+		LatencyMs:     url.Health.LatencyMs,
+		Error:         url.Health.Error,
+		Failures:      url.Health.Failures,
+		LastCheckedAt: &checkedAt,
+	}
+}
+
+// GetBrokenURLs returns the URLs of a workspace whose destination is broken
+func GetBrokenURLs(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	urls, result := queries.GetBrokenURLsQueue(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving broken URLs", utils.ErrGetData, result.Error)
+		return
+	}
+
+	brokenURLs := make([]BrokenURL, 0, len(urls))
+	for _, url := range urls {
+		var domainName string
+		if url.Domain != nil && url.Domain.Verified {
+			domainName = url.Domain.Domain
+		}
+		normalizedDomain, _ := utils.NormalizeDomainName(domainName)
+
+		brokenURLs = append(brokenURLs, BrokenURL{
+			ID:          url.ID,
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			Title:       url.Title,
+			ShortURL:    utils.GetFullShortURL(domainName, url.ShortCode),
+			DomainID:    url.DomainID,
+			DomainName:  normalizedDomain,
+			TotalClicks: url.TotalClicks,
+			Health:      getURLHealth(url),
+		})
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Broken URLs retrieved successfully", nil, brokenURLs)
+}
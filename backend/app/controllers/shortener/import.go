@@ -0,0 +1,530 @@
+package shortener
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/csvcell"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// Bulk import limits
+const (
+	maxImportRows        = 10000
+	maxImportSize        = 10 << 20 // 10 MiB
+	backgroundImportRows = 100      // Imports with more rows run in the background
+)
+
+// ImportRow is a single URL of a bulk import, CSV files use the JSON names as column headers
+type ImportRow struct {
+	OriginalURL string         `json:"original_url"`
+	ShortCode   string         `json:"short_code"`
+	Title       string         `json:"title"`
+	DomainID    ImportDomainID `json:"domain_id"`
+	ExpiresAt   string         `json:"expires_at"` // RFC 3339
+	Tags        []string       `json:"tags"`       // Tag names of the workspace, separated by | in CSV files
+}
+
+// ImportDomainID is the domain of an import row, JSON files may have it as a number or a string
+type ImportDomainID string
+
+// UnmarshalJSON accepts a number, a string or null
+func (id *ImportDomainID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*id = ImportDomainID(value)
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*id = ImportDomainID(number.String())
+	return nil
+}
+
+// importColumns are the CSV columns an import understands
+var importColumns = map[string]bool{
+	"original_url": true,
+	"short_code":   true,
+	"title":        true,
+	"domain_id":    true,
+	"expires_at":   true,
+	"tags":         true,
+}
+
+// ImportURLs creates many URLs at once from a CSV or JSON upload.
+// Small imports are processed right away, larger ones run in the background and are polled with GetImportJob.
+func ImportURLs(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusUnauthorized, "Authentication required", utils.ErrUnauthorized, nil)
+		return
+	}
+
+	mode := c.DefaultQuery("mode", models.ImportModeAtomic)
+	if mode != models.ImportModeAtomic && mode != models.ImportModePartial {
+		utils.FullyResponse(c, http.StatusBadRequest, "mode must be atomic or partial", utils.ErrBadRequest, nil)
+		return
+	}
+
+	rows, err := parseImportRows(c)
+	if err != nil {
+		return
+	}
+
+	if len(rows) == 0 {
+		utils.FullyResponse(c, http.StatusBadRequest, "The import doesn't contain any rows", utils.ErrBadRequest, nil)
+		return
+	}
+	if len(rows) > maxImportRows {
+		errMsg := fmt.Sprintf("An import can contain at most %d rows", maxImportRows)
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return
+	}
+
+	job := models.ImportJob{
+		ID:          encryption.GenerateID(),
+		WorkspaceID: workspaceID.(uint64),
+		UserID:      userID.(uint64),
+		Mode:        mode,
+		Status:      models.ImportStatusPending,
+		TotalRows:   len(rows),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if result := queries.CreateImportJobQueue(job); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating import job", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	if len(rows) > backgroundImportRows {
+		go runImportJob(job, rows)
+		utils.FullyResponse(c, http.StatusAccepted, "Import started", nil, job)
+		return
+	}
+
+	job = runImportJob(job, rows)
+	if job.Status == models.ImportStatusFailed {
+		utils.FullyResponse(c, http.StatusInternalServerError, job.Error, utils.ErrSaveData, job)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Import completed", nil, job)
+}
+
+// GetImportJob returns the status and report of an import job
+func GetImportJob(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	jobID, err := utils.StrToUint64(c.Param("jobID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid import job ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	job, result := queries.GetImportJobByID(workspaceID.(uint64), jobID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Import job not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Import job retrieved successfully", nil, job)
+}
+
+// parseImportRows reads the rows of a JSON or CSV request body, or of the file field of a multipart form.
+// The error response has already been sent when an error is returned.
+func parseImportRows(c *gin.Context) ([]ImportRow, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var reader io.Reader = c.Request.Body
+	format := c.ContentType()
+
+	if format == binding.MIMEMultipartPOSTForm {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "An import file is required", utils.ErrBadRequest, err.Error())
+			return nil, err
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error reading import file", utils.ErrParse, err)
+			return nil, err
+		}
+		defer file.Close()
+
+		reader = file
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".csv":
+			format = "text/csv"
+		case ".json":
+			format = binding.MIMEJSON
+		default:
+			format = fileHeader.Header.Get("Content-Type")
+		}
+	}
+
+	var rows []ImportRow
+	var err error
+	switch format {
+	case "text/csv":
+		rows, err = parseImportCSV(reader)
+	case binding.MIMEJSON:
+		err = json.NewDecoder(reader).Decode(&rows)
+	default:
+		errMsg := "imports must be CSV or JSON"
+		utils.FullyResponse(c, http.StatusUnsupportedMediaType, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid import file", utils.ErrParseData, err.Error())
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// parseImportCSV reads import rows from a CSV file with a header line
+func parseImportCSV(reader io.Reader) ([]ImportRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !importColumns[name] {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["original_url"]; !ok {
+		return nil, errors.New("the original_url column is required")
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			// Exports escape the cells spreadsheet apps would run as a formula
+			return csvcell.Unescape(strings.TrimSpace(record[i]))
+		}
+		return ""
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("an import can contain at most %d rows", maxImportRows)
+		}
+
+		row := ImportRow{
+			OriginalURL: column(record, "original_url"),
+			ShortCode:   column(record, "short_code"),
+			Title:       column(record, "title"),
+			DomainID:    ImportDomainID(column(record, "domain_id")),
+			ExpiresAt:   column(record, "expires_at"),
+		}
+		for _, tag := range strings.Split(column(record, "tags"), "|") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				row.Tags = append(row.Tags, tag)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// runImportJob validates and creates the rows of an import, then stores the report on the job
+func runImportJob(job models.ImportJob, rows []ImportRow) (finished models.ImportJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Log.Sugar().Errorf("Import job %d panicked: %v", job.ID, r)
+			finished = finishImportJob(job, nil, "import failed unexpectedly")
+		}
+	}()
+
+	job.Status = models.ImportStatusRunning
+	if result := queries.UpdateImportJobQueue(job); result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to update import job %d: %v", job.ID, result.Error)
+	}
+
+	importer, err := newURLImporter(job)
+	if err != nil {
+		return finishImportJob(job, nil, "error preparing the import")
+	}
+
+	results := make([]models.ImportRowResult, len(rows))
+	urls := make([]models.URL, len(rows))
+	valid := true
+	for i, row := range rows {
+		results[i].Row = i + 1
+		url, err := importer.prepare(row)
+		if err != nil {
+			results[i].Status = models.ImportRowFailed
+			results[i].Error = err.Error()
+			valid = false
+			continue
+		}
+		urls[i] = url
+		results[i].ShortCode = url.ShortCode
+	}
+
+	if job.Mode == models.ImportModeAtomic {
+		// Every row must be valid, then all of them are created together
+		if !valid {
+			for i := range results {
+				if results[i].Status == "" {
+					results[i].Status = models.ImportRowSkipped
+				}
+			}
+			return finishImportJob(job, results, "")
+		}
+
+		err := queries.CreateURLsInTransaction(urls)
+		// Another URL took a generated short code in the meantime, the rows get new ones
+		for attempt := 0; queries.IsShortCodeConflict(err) && attempt < shortCodeRetries; attempt++ {
+			for i := range urls {
+				if strings.TrimSpace(rows[i].ShortCode) == "" {
+					importer.regenerateShortCode(&urls[i])
+				}
+			}
+			err = queries.CreateURLsInTransaction(urls)
+		}
+		if err != nil {
+			logger.Log.Sugar().Errorf("Failed to create URLs of import job %d: %v", job.ID, err)
+			for i := range results {
+				results[i].Status = models.ImportRowSkipped
+			}
+			return finishImportJob(job, results, "error creating the URLs, nothing was imported")
+		}
+		for i := range results {
+			importer.created(&results[i], urls[i])
+		}
+		return finishImportJob(job, results, "")
+	}
+
+	// Partial mode creates every valid row on its own
+	for i := range results {
+		if results[i].Status != "" {
+			continue
+		}
+		result := queries.CreateURLWithTagsQueue(urls[i])
+		for attempt := 0; strings.TrimSpace(rows[i].ShortCode) == "" && queries.IsShortCodeConflict(result.Error) && attempt < shortCodeRetries; attempt++ {
+			importer.regenerateShortCode(&urls[i])
+			result = queries.CreateURLWithTagsQueue(urls[i])
+		}
+		if result.Error != nil {
+			logger.Log.Sugar().Errorf("Failed to create URL of import job %d: %v", job.ID, result.Error)
+			results[i].Status = models.ImportRowFailed
+			results[i].Error = "error creating short URL"
+			continue
+		}
+		importer.created(&results[i], urls[i])
+	}
+
+	return finishImportJob(job, results, "")
+}
+
+// finishImportJob stores the report of an import job, a non empty errMsg marks the whole job as failed
+func finishImportJob(job models.ImportJob, results []models.ImportRowResult, errMsg string) models.ImportJob {
+	now := time.Now()
+	job.Rows = results
+	job.CompletedAt = &now
+	job.CreatedRows, job.FailedRows = 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case models.ImportRowCreated:
+			job.CreatedRows++
+		case models.ImportRowFailed:
+			job.FailedRows++
+		}
+	}
+
+	job.Status = models.ImportStatusCompleted
+	job.Error = errMsg
+	if errMsg != "" {
+		job.Status = models.ImportStatusFailed
+	}
+
+	if result := queries.UpdateImportJobQueue(job); result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to update import job %d: %v", job.ID, result.Error)
+	}
+	return job
+}
+
+// urlImporter turns import rows into URLs of a workspace, with the same rules as CreateShortURL
+type urlImporter struct {
+	workspaceID  uint64
+	userID       uint64
+	redirectType int
+	tags         map[string]models.Tag // Workspace tags by name
+	domains      map[uint64]string     // Verified domain names by ID
+	slugs        map[string]bool       // Short codes already used by earlier rows, per domain
+}
+
+// newURLImporter loads what every row of an import shares
+func newURLImporter(job models.ImportJob) (*urlImporter, error) {
+	tags, result := queries.GetTagsByWorkspaceID(job.WorkspaceID)
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to load tags of import job %d: %v", job.ID, result.Error)
+		return nil, result.Error
+	}
+
+	importer := &urlImporter{
+		workspaceID:  job.WorkspaceID,
+		userID:       job.UserID,
+		redirectType: getRedirectType(nil, &job.WorkspaceID),
+		tags:         make(map[string]models.Tag, len(tags)),
+		domains:      map[uint64]string{},
+		slugs:        map[string]bool{},
+	}
+	for _, tag := range tags {
+		importer.tags[tag.Name] = tag
+	}
+
+	return importer, nil
+}
+
+// prepare validates a row and builds its URL, the returned error is shown in the report
+func (importer *urlImporter) prepare(row ImportRow) (models.URL, error) {
+	request := ShortenURLRequest{
+		OriginalURL:  strings.TrimSpace(row.OriginalURL),
+		ShortCode:    strings.TrimSpace(row.ShortCode),
+		Title:        strings.TrimSpace(row.Title),
+		RedirectType: &importer.redirectType,
+	}
+
+	if row.DomainID != "" {
+		domainID, err := utils.StrToUint64(string(row.DomainID))
+		if err != nil {
+			return models.URL{}, errors.New("invalid domain ID")
+		}
+		request.DomainID = &domainID
+	}
+
+	if row.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, row.ExpiresAt)
+		if err != nil {
+			return models.URL{}, errors.New("expires_at must be an RFC 3339 time")
+		}
+		request.ExpiresAt = &expiresAt
+	}
+
+	// The same rules as a single URL creation
+	if err := binding.Validator.ValidateStruct(&request); err != nil {
+		return models.URL{}, err
+	}
+	if err := checkShortenRequest(&request, true, &importer.workspaceID); err != nil {
+		return models.URL{}, err
+	}
+
+	tags := make([]models.Tag, 0, len(row.Tags))
+	for _, name := range row.Tags {
+		tag, ok := importer.tags[strings.TrimSpace(name)]
+		if !ok {
+			return models.URL{}, fmt.Errorf("tag %q not found in this workspace", name)
+		}
+		tags = append(tags, tag)
+	}
+
+	// Rows of the same import can't share a short code either
+	domainID := getDomainID(request.DomainID)
+	shortCode := request.ShortCode
+	if shortCode == "" {
+		var err error
+		shortCode, err = generateShortCode(domainID, &importer.workspaceID, func(code string) bool {
+			return importer.slugs[fmt.Sprintf("%d/%s", domainID, code)]
+		})
+		if err != nil {
+			return models.URL{}, errors.New("error generating short code")
+		}
+	}
+
+	slugKey := fmt.Sprintf("%d/%s", domainID, shortCode)
+	if importer.slugs[slugKey] {
+		return models.URL{}, errors.New("custom slug already used by an earlier row")
+	}
+	importer.slugs[slugKey] = true
+
+	url := createURLModel(&request, shortCode, &importer.workspaceID, &importer.userID)
+	url.Tags = tags
+	return url, nil
+}
+
+// regenerateShortCode gives a URL a new generated short code, when another URL took its code before it was created.
+// The current code is kept when no new one can be generated.
+func (importer *urlImporter) regenerateShortCode(url *models.URL) {
+	shortCode, err := generateShortCode(url.DomainID, &importer.workspaceID, func(code string) bool {
+		return importer.slugs[fmt.Sprintf("%d/%s", url.DomainID, code)]
+	})
+	if err != nil {
+		return
+	}
+	importer.slugs[fmt.Sprintf("%d/%s", url.DomainID, shortCode)] = true
+	url.ShortCode = shortCode
+}
+
+// created fills the report of a row whose URL was created
+func (importer *urlImporter) created(result *models.ImportRowResult, url models.URL) {
+	domainName, ok := importer.domains[url.DomainID]
+	if !ok && url.DomainID > 0 {
+		domain, dbResult := queries.GetDomainByID(url.DomainID)
+		if dbResult.Error == nil && domain.Verified {
+			domainName = domain.Domain
+		}
+		importer.domains[url.DomainID] = domainName
+	}
+
+	result.Status = models.ImportRowCreated
+	result.URLID = url.ID
+	result.ShortCode = url.ShortCode
+	result.ShortURL = utils.GetFullShortURL(domainName, url.ShortCode)
+}
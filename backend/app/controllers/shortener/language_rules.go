@@ -0,0 +1,166 @@
+package shortener
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// LanguageRuleRequest represents the request body for creating or updating a language rule
+type LanguageRuleRequest struct {
+	Language       string `json:"language" binding:"required,max=35"`
+	DestinationURL string `json:"destination_url" binding:"required,url"`
+}
+
+// GetLanguageRules returns all language rules of a URL
+func GetLanguageRules(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	rules, result := queries.GetLanguageRulesByURLID(url.ID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving language rules", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Language rules retrieved successfully", nil, rules)
+}
+
+// CreateLanguageRule adds a language based destination to a URL
+func CreateLanguageRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	var request LanguageRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	language := strings.ToLower(request.Language)
+	if !isValidLanguageTag(language) {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid language tag", utils.ErrBadRequest, nil)
+		return
+	}
+
+	exists, err := queries.CheckLanguageRuleExists(url.ID, language)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking language rule", utils.ErrGetData, err)
+		return
+	}
+	if exists {
+		utils.FullyResponse(c, http.StatusConflict, "A language rule for this language already exists", utils.ErrResourceExists, nil)
+		return
+	}
+
+	rule := models.URLLanguageRule{
+		ID:             encryption.GenerateID(),
+		URLID:          url.ID,
+		Language:       language,
+		DestinationURL: request.DestinationURL,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if result := queries.CreateLanguageRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating language rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Language rule created successfully", nil, rule)
+}
+
+// UpdateLanguageRule updates the language or destination of a language rule
+func UpdateLanguageRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid language rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	rule, result := queries.GetLanguageRuleByID(url.ID, ruleID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Language rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request LanguageRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	language := strings.ToLower(request.Language)
+	if !isValidLanguageTag(language) {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid language tag", utils.ErrBadRequest, nil)
+		return
+	}
+
+	// Changing the language must not collide with another rule
+	if language != rule.Language {
+		exists, err := queries.CheckLanguageRuleExists(url.ID, language)
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking language rule", utils.ErrGetData, err)
+			return
+		}
+		if exists {
+			utils.FullyResponse(c, http.StatusConflict, "A language rule for this language already exists", utils.ErrResourceExists, nil)
+			return
+		}
+	}
+
+	rule.Language = language
+	rule.DestinationURL = request.DestinationURL
+
+	if result := queries.UpdateLanguageRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating language rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Language rule updated successfully", nil, rule)
+}
+
+// DeleteLanguageRule removes a language rule from a URL
+func DeleteLanguageRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid language rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteLanguageRuleQueue(url.ID, ruleID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting language rule", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Language rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Language rule deleted successfully", nil, nil)
+}
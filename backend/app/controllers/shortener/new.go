@@ -1,9 +1,7 @@
 package shortener
 
 import (
-	"crypto/rand"
-	"encoding/base64"
-	"errors"
+	"context"
 	"net/http"
 	"time"
 
@@ -16,21 +14,70 @@ import (
 
 // ShortenURLRequest represents the request body for creating a short URL
 type ShortenURLRequest struct {
-	OriginalURL string     `json:"original_url" binding:"required,url"`
-	ShortCode   string     `json:"short_code" binding:"omitempty,max=100"`
-	ExpiresAt   *time.Time `json:"expires_at" binding:"omitempty"`
-	DomainID    *uint64    `json:"domain_id,omitempty"`
+	OriginalURL  string     `json:"original_url" binding:"required,url"`
+	ShortCode    string     `json:"short_code" binding:"omitempty,max=100"`
+	Title        string     `json:"title,omitempty" binding:"omitempty,max=255"`
+	ExpiresAt    *time.Time `json:"expires_at" binding:"omitempty"`
+	DomainID     *uint64    `json:"domain_id,omitempty"`
+	RedirectType *int       `json:"redirect_type,omitempty" binding:"omitempty,oneof=301 302 307 308"`
+	Password     string     `json:"password,omitempty" binding:"omitempty,min=4,max=128"`
+	MaxClicks    *int64     `json:"max_clicks,omitempty" binding:"omitempty,min=1"`
+	ActivatesAt  *time.Time `json:"activates_at,omitempty" binding:"omitempty"`
+	PrelaunchURL string     `json:"prelaunch_url,omitempty" binding:"omitempty,url"`
+	FolderID     string     `json:"folder_id,omitempty"`
+	TagIDs       []string   `json:"tag_ids,omitempty"`
+
+	StickyVariants     bool   `json:"sticky_variants,omitempty"`
+	ForwardPath        bool   `json:"forward_path,omitempty"`
+	QueryPassthrough   string `json:"query_passthrough,omitempty" binding:"omitempty,oneof=off replace keep append"`
+	UTMSource          string `json:"utm_source,omitempty" binding:"omitempty,max=255"`
+	UTMMedium          string `json:"utm_medium,omitempty" binding:"omitempty,max=255"`
+	UTMCampaign        string `json:"utm_campaign,omitempty" binding:"omitempty,max=255"`
+	UTMTerm            string `json:"utm_term,omitempty" binding:"omitempty,max=255"`
+	UTMContent         string `json:"utm_content,omitempty" binding:"omitempty,max=255"`
+	IOSDeepLink        string `json:"ios_deep_link,omitempty" binding:"omitempty,uri,max=2048"`
+	IOSFallbackURL     string `json:"ios_fallback_url,omitempty" binding:"omitempty,url"`
+	AndroidDeepLink    string `json:"android_deep_link,omitempty" binding:"omitempty,uri,max=2048"`
+	AndroidFallbackURL string `json:"android_fallback_url,omitempty" binding:"omitempty,url"`
+	OGTitle            string `json:"og_title,omitempty" binding:"omitempty,max=255"`
+	OGDescription      string `json:"og_description,omitempty" binding:"omitempty,max=1024"`
+	OGImageURL         string `json:"og_image_url,omitempty" binding:"omitempty,url"`
 }
 
 // ShortenURLResponse represents the response after creating a short URL
 type ShortenURLResponse struct {
-	ShortCode   string     `json:"short_code"`
-	OriginalURL string     `json:"original_url"`
-	ShortURL    string     `json:"short_url"`
-	DomainID    uint64     `json:"domain_id,omitempty"`
-	DomainName  string     `json:"domain_name,omitempty"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ShortCode          string       `json:"short_code"`
+	OriginalURL        string       `json:"original_url"`
+	Title              string       `json:"title,omitempty"`
+	ShortURL           string       `json:"short_url"`
+	DomainID           uint64       `json:"domain_id,omitempty"`
+	DomainName         string       `json:"domain_name,omitempty"`
+	RedirectType       int          `json:"redirect_type"`
+	PasswordProtected  bool         `json:"password_protected"`
+	ExpiresAt          *time.Time   `json:"expires_at,omitempty"`
+	MaxClicks          *int64       `json:"max_clicks,omitempty"`
+	ActivatesAt        *time.Time   `json:"activates_at,omitempty"`
+	PrelaunchURL       string       `json:"prelaunch_url,omitempty"`
+	Status             string       `json:"status"`
+	FolderID           *uint64      `json:"folder_id,string,omitempty"`
+	Tags               []models.Tag `json:"tags"`
+	StickyVariants     bool         `json:"sticky_variants"`
+	ForwardPath        bool         `json:"forward_path"`
+	QueryPassthrough   string       `json:"query_passthrough"`
+	UTMSource          string       `json:"utm_source,omitempty"`
+	UTMMedium          string       `json:"utm_medium,omitempty"`
+	UTMCampaign        string       `json:"utm_campaign,omitempty"`
+	UTMTerm            string       `json:"utm_term,omitempty"`
+	UTMContent         string       `json:"utm_content,omitempty"`
+	IOSDeepLink        string       `json:"ios_deep_link,omitempty"`
+	IOSFallbackURL     string       `json:"ios_fallback_url,omitempty"`
+	AndroidDeepLink    string       `json:"android_deep_link,omitempty"`
+	AndroidFallbackURL string       `json:"android_fallback_url,omitempty"`
+	OGTitle            string       `json:"og_title,omitempty"`
+	OGDescription      string       `json:"og_description,omitempty"`
+	OGImageURL         string       `json:"og_image_url,omitempty"`
+	BlockedReason      string       `json:"blocked_reason,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
 }
 
 // CreateShortURL handles the creation of a new short URL
@@ -40,11 +87,6 @@ func CreateShortURL(c *gin.Context) {
 		return // Error response already sent in the validation function
 	}
 
-	shortCode, err := getShortCode(c, request)
-	if err != nil {
-		return
-	}
-
 	// Get user ID if authenticated
 	var userIDPtr *uint64
 	if userID, exists := c.Get("userID"); exists {
@@ -59,12 +101,33 @@ func CreateShortURL(c *gin.Context) {
 		workspaceIDPtr = &id
 	}
 
+	shortCode, err := getShortCode(c, request, workspaceIDPtr)
+	if err != nil {
+		return
+	}
+
 	urlModel := createURLModel(request, shortCode, workspaceIDPtr, userIDPtr)
 
-	if err := saveURLToDatabase(c, urlModel); err != nil {
+	// Organize the URL in the workspace
+	if urlModel.FolderID, err = getRequestFolder(c, request.FolderID); err != nil {
+		return
+	}
+	if urlModel.Tags, err = getRequestTags(c, request.TagIDs); err != nil {
 		return
 	}
 
+	// Protect the URL with a password if one was provided
+	if request.Password != "" {
+		if urlModel.Password, err = hashURLPassword(c, request.Password); err != nil {
+			return
+		}
+	}
+
+	if urlModel, err = saveURLToDatabase(c, urlModel, request.ShortCode == ""); err != nil {
+		return
+	}
+	shortCode = urlModel.ShortCode
+
 	// Get domain info if a domain ID was provided
 	var domainName string
 	if urlModel.DomainID > 0 {
@@ -81,13 +144,37 @@ func CreateShortURL(c *gin.Context) {
 	normalizedDomain, _ := utils.NormalizeDomainName(domainName)
 
 	response := ShortenURLResponse{
-		ShortCode:   shortCode,
-		OriginalURL: request.OriginalURL,
-		ShortURL:    shortURL,
-		DomainID:    urlModel.DomainID,
-		DomainName:  normalizedDomain,
-		ExpiresAt:   request.ExpiresAt,
-		CreatedAt:   urlModel.CreatedAt,
+		ShortCode:          shortCode,
+		OriginalURL:        request.OriginalURL,
+		Title:              request.Title,
+		ShortURL:           shortURL,
+		DomainID:           urlModel.DomainID,
+		DomainName:         normalizedDomain,
+		RedirectType:       urlModel.RedirectType,
+		PasswordProtected:  urlModel.Password != "",
+		ExpiresAt:          request.ExpiresAt,
+		MaxClicks:          urlModel.MaxClicks,
+		ActivatesAt:        urlModel.ActivatesAt,
+		PrelaunchURL:       urlModel.PrelaunchURL,
+		Status:             getURLStatus(urlModel, time.Now()),
+		FolderID:           urlModel.FolderID,
+		Tags:               urlModel.Tags,
+		StickyVariants:     urlModel.StickyVariants,
+		ForwardPath:        urlModel.ForwardPath,
+		QueryPassthrough:   urlModel.QueryPassthrough,
+		UTMSource:          urlModel.UTMSource,
+		UTMMedium:          urlModel.UTMMedium,
+		UTMCampaign:        urlModel.UTMCampaign,
+		UTMTerm:            urlModel.UTMTerm,
+		UTMContent:         urlModel.UTMContent,
+		IOSDeepLink:        urlModel.IOSDeepLink,
+		IOSFallbackURL:     urlModel.IOSFallbackURL,
+		AndroidDeepLink:    urlModel.AndroidDeepLink,
+		AndroidFallbackURL: urlModel.AndroidFallbackURL,
+		OGTitle:            urlModel.OGTitle,
+		OGDescription:      urlModel.OGDescription,
+		OGImageURL:         urlModel.OGImageURL,
+		CreatedAt:          urlModel.CreatedAt,
 	}
 
 	utils.FullyResponse(c, http.StatusCreated, "Short URL created successfully", nil, response)
@@ -101,30 +188,121 @@ func validateShortenRequest(c *gin.Context) (*ShortenURLRequest, error) {
 		return nil, err
 	}
 
+	_, authenticated := c.Get("userID")
+	var workspaceIDPtr *uint64
+	if workspaceID, exists := c.Get("workspaceID"); exists {
+		id := workspaceID.(uint64)
+		workspaceIDPtr = &id
+	}
+
+	if err := checkShortenRequest(&request, authenticated, workspaceIDPtr); err != nil {
+		if err.Err != nil {
+			utils.ServerErrorResponse(c, err.StatusCode, err.Message, err.ErrorCode, err.Err)
+		} else {
+			utils.FullyResponse(c, err.StatusCode, err.Message, err.ErrorCode, nil)
+		}
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// shortenRequestError describes why a shorten request was rejected
+type shortenRequestError struct {
+	StatusCode int
+	ErrorCode  string
+	Message    string
+	Err        error // The underlying error of a server error
+}
+
+func (e *shortenRequestError) Error() string {
+	return e.Message
+}
+
+// workspaceOnlyField returns the JSON name of the first field set on the request that only workspace URLs may use.
+// URLs without a workspace can never be edited or deleted, so they get no password, limits, schedule,
+// platform destinations, social card or path and query options.
+func workspaceOnlyField(request *ShortenURLRequest) string {
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"password", request.Password != ""},
+		{"max_clicks", request.MaxClicks != nil},
+		{"activates_at", request.ActivatesAt != nil},
+		{"prelaunch_url", request.PrelaunchURL != ""},
+		{"folder_id", request.FolderID != ""},
+		{"tag_ids", len(request.TagIDs) > 0},
+		{"sticky_variants", request.StickyVariants},
+		{"forward_path", request.ForwardPath},
+		{"query_passthrough", request.QueryPassthrough != "" && request.QueryPassthrough != models.QueryPassthroughOff},
+		{"utm_source", request.UTMSource != ""},
+		{"utm_medium", request.UTMMedium != ""},
+		{"utm_campaign", request.UTMCampaign != ""},
+		{"utm_term", request.UTMTerm != ""},
+		{"utm_content", request.UTMContent != ""},
+		{"ios_deep_link", request.IOSDeepLink != ""},
+		{"ios_fallback_url", request.IOSFallbackURL != ""},
+		{"android_deep_link", request.AndroidDeepLink != ""},
+		{"android_fallback_url", request.AndroidFallbackURL != ""},
+		{"og_title", request.OGTitle != ""},
+		{"og_description", request.OGDescription != ""},
+		{"og_image_url", request.OGImageURL != ""},
+	}
+	for _, field := range fields {
+		if field.set {
+			return field.name
+		}
+	}
+	return ""
+}
+
+// checkShortenRequest applies the rules of a shorten request that struct binding can't express
+func checkShortenRequest(request *ShortenURLRequest, authenticated bool, workspaceID *uint64) *shortenRequestError {
+	if workspaceID == nil {
+		if field := workspaceOnlyField(request); field != "" {
+			return &shortenRequestError{http.StatusUnauthorized, utils.ErrUnauthorized, field + " requires a workspace", nil}
+		}
+	}
+
+	// Validate the activation window
+	if errMsg := activationWindowError(request.ActivatesAt, request.ExpiresAt, request.PrelaunchURL); errMsg != "" {
+		return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, errMsg, nil}
+	}
+
+	// Deep links are opened by the app page of the short domain, script schemes must not get there
+	if request.IOSDeepLink != "" && !isValidDeepLink(request.IOSDeepLink) {
+		return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, "iOS deep link must be a web link or an app URI", nil}
+	}
+	if request.AndroidDeepLink != "" && !isValidDeepLink(request.AndroidDeepLink) {
+		return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, "Android deep link must be a web link or an app URI", nil}
+	}
+
+	// Reject blocked, malicious and private network destinations
+	destinations := append([]string{request.OriginalURL, request.PrelaunchURL, request.IOSFallbackURL, request.AndroidFallbackURL, request.OGImageURL},
+		webDeepLinks(request.IOSDeepLink, request.AndroidDeepLink)...)
+	if message, violation := checkDestinationSafety(context.Background(), destinations...); violation != nil {
+		return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, message, nil}
+	}
+
 	// Validate custom slug if present
 	if request.ShortCode != "" {
-		_, exists := c.Get("userID")
-		if !exists {
-			utils.FullyResponse(c, http.StatusUnauthorized, "custom slugs require authentication", utils.ErrUnauthorized, nil)
-			return nil, errors.New("custom slugs require authentication")
+		if !authenticated {
+			return &shortenRequestError{http.StatusUnauthorized, utils.ErrUnauthorized, "custom slugs require authentication", nil}
 		}
 
 		if !isValidCustomSlug(request.ShortCode) {
 			errMsg := "custom slug must contain only alphanumeric characters and hyphens, and cannot start or end with a hyphen"
-			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
-			return nil, errors.New(errMsg)
+			return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, errMsg, nil}
 		}
 
 		// Check if the slug already exists in the database
 		exists, err := checkSlugExists(request.ShortCode, request.DomainID)
 		if err != nil {
-			utils.ServerErrorResponse(c, http.StatusInternalServerError, "error checking custom slug", utils.ErrGetData, err)
-			return nil, err
+			return &shortenRequestError{http.StatusInternalServerError, utils.ErrGetData, "error checking custom slug", err}
 		}
 		if exists {
-			errMsg := "custom slug already in use; please choose a different one"
-			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
-			return nil, errors.New(errMsg)
+			return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, "custom slug already in use; please choose a different one", nil}
 		}
 	}
 
@@ -133,48 +311,37 @@ func validateShortenRequest(c *gin.Context) (*ShortenURLRequest, error) {
 		// Check if domain exists and is verified
 		domain, result := queries.GetDomainByID(*request.DomainID)
 		if result.Error != nil || domain == nil {
-			errMsg := "invalid domain ID"
-			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
-			return nil, errors.New(errMsg)
+			return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, "invalid domain ID", nil}
 		}
 
 		// Ensure domain is verified
 		if !domain.Verified {
-			errMsg := "domain has not been verified yet"
-			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
-			return nil, errors.New(errMsg)
+			return &shortenRequestError{http.StatusBadRequest, utils.ErrBadRequest, "domain has not been verified yet", nil}
 		}
 
 		// Check if user has permission for this domain
-		_, userExists := c.Get("userID")
-		workspaceID, workspaceExists := c.Get("workspaceID")
-
-		if !userExists {
-			errMsg := "authentication required to use custom domains"
-			utils.FullyResponse(c, http.StatusUnauthorized, errMsg, utils.ErrUnauthorized, nil)
-			return nil, errors.New(errMsg)
+		if !authenticated {
+			return &shortenRequestError{http.StatusUnauthorized, utils.ErrUnauthorized, "authentication required to use custom domains", nil}
 		}
 
 		// If domain belongs to a workspace, ensure user has access
-		if domain.WorkspaceID != nil && workspaceExists && *domain.WorkspaceID != workspaceID.(uint64) {
-			errMsg := "you don't have permission to use this domain"
-			utils.FullyResponse(c, http.StatusForbidden, errMsg, utils.ErrForbidden, nil)
-			return nil, errors.New(errMsg)
+		if domain.WorkspaceID != nil && workspaceID != nil && *domain.WorkspaceID != *workspaceID {
+			return &shortenRequestError{http.StatusForbidden, utils.ErrForbidden, "you don't have permission to use this domain", nil}
 		}
 	}
 
-	return &request, nil
+	return nil
 }
 
 // getShortCode generates or validates a short code for the URL
-func getShortCode(c *gin.Context, request *ShortenURLRequest) (string, error) {
+func getShortCode(c *gin.Context, request *ShortenURLRequest, workspaceID *uint64) (string, error) {
 	// If custom slug is provided, check if it's available
 	if request.ShortCode != "" {
 		return request.ShortCode, nil
 	}
 
-	// Generate a random short code
-	shortCode, err := generateRandomShortCode(6)
+	// Generate a random short code no other URL of the domain uses
+	shortCode, err := generateShortCode(getDomainID(request.DomainID), workspaceID, nil)
 	if err != nil {
 		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error generating short code", utils.ErrParse, err)
 		return "", err
@@ -183,23 +350,6 @@ func getShortCode(c *gin.Context, request *ShortenURLRequest) (string, error) {
 	return shortCode, nil
 }
 
-// generateRandomShortCode creates a random string for the URL shortcode
-func generateRandomShortCode(length int) (string, error) {
-	buffer := make([]byte, length)
-	_, err := rand.Read(buffer)
-	if err != nil {
-		return "", err
-	}
-
-	// Convert to base64 and remove non-alphanumeric chars
-	shortCode := base64.URLEncoding.EncodeToString(buffer)
-	// Truncate to desired length
-	if len(shortCode) > length {
-		shortCode = shortCode[:length]
-	}
-	return shortCode, nil
-}
-
 // checkSlugExists checks if a custom slug is already in use
 func checkSlugExists(slug string, domainID *uint64) (bool, error) {
 	if domainID == nil {
@@ -213,16 +363,64 @@ func checkSlugExists(slug string, domainID *uint64) (bool, error) {
 // createURLModel creates a new URL model with the request data
 func createURLModel(request *ShortenURLRequest, shortCode string, workspaceID *uint64, userID *uint64) models.URL {
 	return models.URL{
-		ID:          encryption.GenerateID(),
-		WorkspaceID: workspaceID,
-		DomainID:    getDomainID(request.DomainID),
-		OriginalURL: request.OriginalURL,
-		ShortCode:   shortCode,
-		ExpiresAt:   request.ExpiresAt,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		TotalClicks: 0,
+		ID:           encryption.GenerateID(),
+		WorkspaceID:  workspaceID,
+		DomainID:     getDomainID(request.DomainID),
+		OriginalURL:  request.OriginalURL,
+		Title:        request.Title,
+		ShortCode:    shortCode,
+		RedirectType: getRedirectType(request.RedirectType, workspaceID),
+		ExpiresAt:    request.ExpiresAt,
+		MaxClicks:    request.MaxClicks,
+		ActivatesAt:  request.ActivatesAt,
+		PrelaunchURL: request.PrelaunchURL,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		TotalClicks:  0,
+
+		StickyVariants:     request.StickyVariants,
+		ForwardPath:        request.ForwardPath,
+		QueryPassthrough:   getQueryPassthrough(request.QueryPassthrough),
+		UTMSource:          request.UTMSource,
+		UTMMedium:          request.UTMMedium,
+		UTMCampaign:        request.UTMCampaign,
+		UTMTerm:            request.UTMTerm,
+		UTMContent:         request.UTMContent,
+		IOSDeepLink:        request.IOSDeepLink,
+		IOSFallbackURL:     request.IOSFallbackURL,
+		AndroidDeepLink:    request.AndroidDeepLink,
+		AndroidFallbackURL: request.AndroidFallbackURL,
+		OGTitle:            request.OGTitle,
+		OGDescription:      request.OGDescription,
+		OGImageURL:         request.OGImageURL,
+	}
+}
+
+// getQueryPassthrough returns the requested query passthrough mode, the query string is dropped by default
+func getQueryPassthrough(mode string) string {
+	if mode == "" {
+		return models.QueryPassthroughOff
+	}
+	return mode
+}
+
+// getRedirectType returns the requested redirect type, falling back to the workspace default.
+// Anonymous links can't be edited later, so they keep using a permanent redirect.
+func getRedirectType(redirectType *int, workspaceID *uint64) int {
+	if redirectType != nil {
+		return *redirectType
 	}
+
+	if workspaceID == nil {
+		return models.RedirectTypeMovedPermanently
+	}
+
+	workspace, result := queries.GetWorkspaceQueueByID(*workspaceID)
+	if result.Error != nil || !models.IsValidRedirectType(workspace.DefaultRedirectType) {
+		return models.DefaultRedirectType
+	}
+
+	return workspace.DefaultRedirectType
 }
 
 // getDomainID returns 0 if domainID is nil, otherwise returns the value
@@ -246,13 +444,31 @@ func getDomainID(domainID *uint64) uint64 {
 	return 0
 }
 
-// saveURLToDatabase saves the new URL to the database
-func saveURLToDatabase(c *gin.Context, urlModel models.URL) error {
-	result := queries.CreateURLQueue(urlModel)
+// saveURLToDatabase saves the new URL to the database. When another URL took its generated short code in
+// the meantime, it is saved again with a new one. The saved URL is returned.
+func saveURLToDatabase(c *gin.Context, urlModel models.URL, generated bool) (models.URL, error) {
+	for attempt := 0; ; attempt++ {
+		result := queries.CreateURLQueue(urlModel)
+		if result.Error == nil && result.RowsAffected > 0 {
+			return urlModel, nil
+		}
+
+		if queries.IsShortCodeConflict(result.Error) {
+			if !generated {
+				errMsg := "custom slug already in use; please choose a different one"
+				utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+				return urlModel, result.Error
+			}
+			if attempt < shortCodeRetries {
+				shortCode, err := generateShortCode(urlModel.DomainID, urlModel.WorkspaceID, nil)
+				if err == nil {
+					urlModel.ShortCode = shortCode
+					continue
+				}
+			}
+		}
 
-	if result.Error != nil || result.RowsAffected == 0 {
 		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating short URL", utils.ErrSaveData, result.Error)
-		return result.Error
+		return urlModel, result.Error
 	}
-	return nil
 }
@@ -0,0 +1,26 @@
+package shortener
+
+import (
+	"embed"
+	"html/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/pkg/logger"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pageTemplates holds the HTML pages served on short URLs instead of a redirect
+var pageTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// renderPage renders one of the embedded HTML templates
+func renderPage(c *gin.Context, statusCode int, name string, data interface{}) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	c.Status(statusCode)
+
+	if err := pageTemplates.ExecuteTemplate(c.Writer, name, data); err != nil {
+		logger.Log.Sugar().Errorf("Failed to render page %s: %v", name, err)
+	}
+}
@@ -0,0 +1,75 @@
+package shortener
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// previewPageData is the data rendered into the preview page
+type previewPageData struct {
+	Title             string
+	ShortURL          string
+	ShortDomain       string
+	Destination       string
+	PasswordProtected bool
+	Status            string
+	CreatedAt         time.Time
+	ContinueURL       string
+}
+
+// PreviewURL shows where a short URL goes without following it.
+// Previews are not counted as clicks and are not tracked in analytics.
+func PreviewURL(c *gin.Context) {
+	shortCode := strings.TrimSuffix(c.Param("shortCode"), "+")
+	if shortCode == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Short code is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	url, err := getURLByShortCode(c, shortCode)
+	if err != nil {
+		return
+	}
+
+	var domainName string
+	if url.DomainID > 0 {
+		domain, result := queries.GetDomainByID(url.DomainID)
+		if result.Error == nil && domain.Verified {
+			domainName = domain.Domain
+		}
+	}
+	shortURL := utils.GetFullShortURL(domainName, url.ShortCode)
+	shortDomain, _ := utils.NormalizeDomainName(domainName)
+	if shortDomain == "" {
+		shortDomain = utils.GetDefaultShortDomain()
+	}
+
+	data := previewPageData{
+		Title:             url.Title,
+		ShortURL:          shortURL,
+		ShortDomain:       shortDomain,
+		PasswordProtected: url.Password != "",
+		Status:            getURLStatus(url, time.Now()),
+		CreatedAt:         url.CreatedAt,
+		ContinueURL:       "/" + url.ShortCode,
+	}
+
+	// The destination of a protected URL is only revealed after unlocking it, and only while the URL is
+	// active: a scheduled URL shows where it goes before launch, an expired or blocked one nothing
+	if !data.PasswordProtected {
+		switch data.Status {
+		case models.URLStatusActive:
+			data.Destination = composeDestinationURL(url, url.OriginalURL, "", nil)
+		case models.URLStatusScheduled:
+			data.Destination = url.PrelaunchURL
+		}
+	}
+
+	renderPage(c, http.StatusOK, "preview.html", data)
+}
@@ -0,0 +1,113 @@
+package shortener
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/throttle"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// unlockCookieDuration is how long a visitor can follow a protected link without re-entering the password
+const unlockCookieDuration = time.Hour
+
+// Password attempts are refused for a while after too many from one client. Attempts on one link from all
+// clients are only slowed down by a few seconds, so guesses from elsewhere can't lock visitors out of it.
+var (
+	unlockIPThrottle   = throttle.New(5, time.Second, 15*time.Minute)
+	unlockLinkThrottle = throttle.New(20, 250*time.Millisecond, 3*time.Second)
+)
+
+// unlockPageData is the data rendered into the unlock form
+type unlockPageData struct {
+	Action string
+	Error  string
+}
+
+// checkURLUnlocked checks if the visitor may follow a password protected URL.
+// When it returns false the unlock form has already been sent.
+func checkURLUnlocked(c *gin.Context, url models.URL) bool {
+	if url.Password == "" {
+		return true
+	}
+
+	// A valid unlock cookie skips the form
+	if cookie, err := c.Cookie(unlockCookieName(url.ID)); err == nil {
+		value, err := encryption.VerifySignedValue(cookie)
+		if err == nil && value == unlockCookieValue(url) {
+			return true
+		}
+	}
+
+	data := unlockPageData{Action: c.Request.URL.RequestURI()}
+
+	if c.Request.Method != http.MethodPost {
+		renderPage(c, http.StatusUnauthorized, "unlock.html", data)
+		return false
+	}
+
+	// Both throttles count the attempt before the password is compared, a correct password resets them
+	ipKey, linkKey := c.ClientIP(), utils.Uint64ToStr(url.ID)
+	if wait, allowed := unlockIPThrottle.Allow(ipKey); !allowed {
+		seconds := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		data.Error = fmt.Sprintf("Too many incorrect passwords, please try again in %d seconds.", seconds)
+		renderPage(c, http.StatusTooManyRequests, "unlock.html", data)
+		return false
+	}
+	if delay := unlockLinkThrottle.Delay(linkKey); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			timer.Stop()
+			c.Abort()
+			return false
+		}
+	}
+
+	match, err := encryption.ComparePasswordAndHash(c.PostForm("password"), url.Password)
+	if err != nil || !match {
+		data.Error = "Incorrect password, please try again."
+		renderPage(c, http.StatusUnauthorized, "unlock.html", data)
+		return false
+	}
+	unlockIPThrottle.Reset(ipKey)
+	unlockLinkThrottle.Reset(linkKey)
+
+	signed := encryption.SignValue(unlockCookieValue(url), time.Now().Add(unlockCookieDuration))
+	c.SetCookie(unlockCookieName(url.ID), signed, int(unlockCookieDuration.Seconds()), "/", "", utils.IsCookieSecure(), true)
+
+	return true
+}
+
+// unlockCookieName returns the name of the unlock cookie for a URL
+func unlockCookieName(urlID uint64) string {
+	return "zipt_unlock_" + utils.Uint64ToStr(urlID)
+}
+
+// unlockCookieValue binds the unlock cookie to the current password hash,
+// so changing the password invalidates every issued cookie
+func unlockCookieValue(url models.URL) string {
+	digest := sha256.Sum256([]byte(url.Password))
+	return utils.Uint64ToStr(url.ID) + ":" + hex.EncodeToString(digest[:8])
+}
+
+// hashURLPassword hashes the password of a protected URL
+func hashURLPassword(c *gin.Context, password string) (string, error) {
+	hashedPassword, err := encryption.HashPassword(password)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error hashing password", utils.ErrHashData, err)
+		return "", err
+	}
+
+	return hashedPassword, nil
+}
@@ -0,0 +1,265 @@
+package shortener
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Register the JPEG decoder for workspace logos
+	_ "image/png"  // Register the PNG decoder for workspace logos
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/qrcode"
+	store "github.com/yorukot/zipt/pkg/s3"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// QR code limits and defaults
+const (
+	defaultQRSize   = 512
+	minQRSize       = 64
+	maxQRSize       = 2048
+	maxPublicQRSize = 1024 // Public QR codes are rendered for anyone, without an account
+	defaultQRMargin = 4
+	maxQRMargin     = 16
+)
+
+// qrScanParam is added to the short URL encoded in QR codes, so scans can be told apart from direct clicks
+const qrScanParam = "_qr"
+
+// qrContentTypes maps each QR code format to its content type
+var qrContentTypes = map[string]string{
+	"png": "image/png",
+	"svg": "image/svg+xml",
+}
+
+// qrOptions is how a QR code is rendered
+type qrOptions struct {
+	Format     string
+	Size       int
+	Margin     int
+	Level      qrcode.Level
+	Foreground color.NRGBA
+	Background color.NRGBA
+	Logo       bool
+}
+
+// cacheKey identifies the rendered image of the content with these options in object storage
+func (options qrOptions) cacheKey(content string, logoVersion time.Time) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%d|%v|%v|%t|%d",
+		content, options.Size, options.Margin, options.Level, options.Foreground, options.Background, options.Logo, logoVersion.UnixNano())))
+	return "qr/" + hex.EncodeToString(hash[:]) + "." + options.Format
+}
+
+// GetURLQRCode renders the QR code of a URL of the workspace
+func GetURLQRCode(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	renderQRCode(c, url, maxQRSize)
+}
+
+// QRCodeURL renders the QR code of a short URL, it is reached by appending .qr to the short URL
+func QRCodeURL(c *gin.Context) {
+	shortCode := strings.TrimSuffix(c.Param("shortCode"), ".qr")
+	if shortCode == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Short code is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	url, err := getURLByShortCode(c, shortCode)
+	if err != nil {
+		return
+	}
+
+	renderQRCode(c, url, maxPublicQRSize)
+}
+
+// renderQRCode responds with the QR code of the short URL, rendered with the options of the query string
+// up to maxSize pixels. Rendered images are cached in object storage when it is configured.
+func renderQRCode(c *gin.Context, url models.URL, maxSize int) {
+	options, err := getQROptions(c, maxSize)
+	if err != nil {
+		return
+	}
+
+	var domainName string
+	if url.DomainID > 0 {
+		domain, result := queries.GetDomainByID(url.DomainID)
+		if result.Error == nil && domain.Verified {
+			domainName = domain.Domain
+		}
+	}
+	content := utils.GetFullShortURL(domainName, url.ShortCode) + "?" + qrScanParam + "=1"
+
+	var logo models.WorkspaceLogo
+	if options.Logo {
+		if logo, err = getQRLogo(c, url); err != nil {
+			return
+		}
+	}
+
+	key := options.cacheKey(content, logo.UpdatedAt)
+	if store.Enabled() {
+		if data, err := store.GetObject(c.Request.Context(), key); err == nil {
+			sendQRCode(c, options, data)
+			return
+		}
+	}
+
+	code, err := qrcode.Encode([]byte(content), options.Level)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error encoding QR code", utils.ErrParseData, err)
+		return
+	}
+
+	style := qrcode.Style{
+		Size:       options.Size,
+		Margin:     options.Margin,
+		Foreground: options.Foreground,
+		Background: options.Background,
+	}
+	if options.Logo {
+		style.Logo, _, err = image.Decode(bytes.NewReader(logo.Data))
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error decoding workspace logo", utils.ErrParseData, err)
+			return
+		}
+	}
+
+	var data []byte
+	if options.Format == "svg" {
+		data, err = code.SVG(style)
+	} else {
+		data, err = code.PNG(style)
+	}
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error rendering QR code", utils.ErrParseData, err)
+		return
+	}
+
+	// Caching is best effort, the image is served either way
+	if store.Enabled() {
+		go func() {
+			if err := store.PutObject(context.Background(), key, qrContentTypes[options.Format], data); err != nil {
+				logger.Log.Sugar().Warnf("Failed to cache QR code %s: %v", key, err)
+			}
+		}()
+	}
+
+	sendQRCode(c, options, data)
+}
+
+// sendQRCode writes the rendered QR code, as an attachment when download is set
+func sendQRCode(c *gin.Context, options qrOptions, data []byte) {
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="qrcode.%s"`, options.Format))
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, qrContentTypes[options.Format], data)
+}
+
+// getQROptions reads the rendering options from the query string, the size is at most maxSize pixels.
+// The error response has already been sent when an error is returned.
+func getQROptions(c *gin.Context, maxSize int) (qrOptions, error) {
+	options := qrOptions{
+		Format: c.DefaultQuery("format", "png"),
+		Size:   defaultQRSize,
+		Margin: defaultQRMargin,
+		Level:  qrcode.Medium,
+		Logo:   c.Query("logo") == "true",
+	}
+
+	if _, ok := qrContentTypes[options.Format]; !ok {
+		utils.FullyResponse(c, http.StatusBadRequest, "format must be png or svg", utils.ErrBadRequest, nil)
+		return options, errors.New("invalid format")
+	}
+
+	if value := c.Query("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < minQRSize || size > maxSize {
+			utils.FullyResponse(c, http.StatusBadRequest, fmt.Sprintf("size must be between %d and %d", minQRSize, maxSize), utils.ErrBadRequest, nil)
+			return options, errors.New("invalid size")
+		}
+		options.Size = size
+	}
+
+	if value := c.Query("margin"); value != "" {
+		margin, err := strconv.Atoi(value)
+		if err != nil || margin < 0 || margin > maxQRMargin {
+			utils.FullyResponse(c, http.StatusBadRequest, fmt.Sprintf("margin must be between 0 and %d", maxQRMargin), utils.ErrBadRequest, nil)
+			return options, errors.New("invalid margin")
+		}
+		options.Margin = margin
+	}
+
+	// A logo hides part of the symbol, so it needs a higher error correction level
+	if options.Logo {
+		options.Level = qrcode.High
+	}
+	if value := c.Query("level"); value != "" {
+		level, err := qrcode.ParseLevel(value)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "level must be L, M, Q or H", utils.ErrBadRequest, nil)
+			return options, err
+		}
+		if options.Logo && level < qrcode.Quartile {
+			utils.FullyResponse(c, http.StatusBadRequest, "A QR code with a logo needs level Q or H", utils.ErrBadRequest, nil)
+			return options, errors.New("level too low for a logo")
+		}
+		options.Level = level
+	}
+
+	colors := []struct {
+		param    string
+		fallback string
+		target   *color.NRGBA
+	}{
+		{"fg", "#000000", &options.Foreground},
+		{"bg", "#ffffff", &options.Background},
+	}
+	for _, option := range colors {
+		parsed, err := qrcode.ParseHexColor(c.DefaultQuery(option.param, option.fallback))
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, option.param+" must be a #RGB, #RRGGBB or #RRGGBBAA hex color", utils.ErrBadRequest, nil)
+			return options, err
+		}
+		*option.target = parsed
+	}
+
+	return options, nil
+}
+
+// getQRLogo returns the logo of the workspace of the URL.
+// The error response has already been sent when an error is returned.
+func getQRLogo(c *gin.Context, url models.URL) (models.WorkspaceLogo, error) {
+	if url.WorkspaceID == nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Only URLs of a workspace can embed a logo", utils.ErrBadRequest, nil)
+		return models.WorkspaceLogo{}, errors.New("url without workspace")
+	}
+
+	logo, result := queries.GetWorkspaceLogoQueue(*url.WorkspaceID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving workspace logo", utils.ErrGetData, result.Error)
+		return logo, result.Error
+	}
+	if logo.WorkspaceID == 0 {
+		utils.FullyResponse(c, http.StatusBadRequest, "The workspace has no logo", utils.ErrBadRequest, nil)
+		return logo, errors.New("workspace has no logo")
+	}
+
+	return logo, nil
+}
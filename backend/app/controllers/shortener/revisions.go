@@ -0,0 +1,205 @@
+package shortener
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// GetURLRevisions returns the change history of a URL, the newest revision first
+func GetURLRevisions(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	revisions, result := queries.GetURLRevisionsByURLID(url.ID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving revisions", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Revisions retrieved successfully", nil, revisions)
+}
+
+// RollbackURL restores the slug, destination, domain and expiry of a URL from one of its revisions.
+// The rollback itself is recorded as a new revision.
+func RollbackURL(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	revisionID, err := utils.StrToUint64(c.Param("revisionID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid revision ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	revision, result := queries.GetURLRevisionByID(url.ID, revisionID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Revision not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	restored := url
+	restored.ShortCode = revision.ShortCode
+	restored.OriginalURL = revision.OriginalURL
+	restored.DomainID = revision.DomainID
+	restored.ExpiresAt = revision.ExpiresAt
+	restored.UpdatedAt = time.Now()
+
+	// The URL may have been scheduled since the revision was made
+	if errMsg := activationWindowError(restored.ActivatesAt, restored.ExpiresAt, restored.PrelaunchURL); errMsg != "" {
+		utils.FullyResponse(c, http.StatusConflict, "This revision can't be restored, the "+errMsg, utils.ErrBadRequest, nil)
+		return
+	}
+
+	if len(getRevisionChanges(url, restored)) == 0 {
+		utils.FullyResponse(c, http.StatusBadRequest, "The URL already matches this revision", utils.ErrBadRequest, nil)
+		return
+	}
+
+	// The domain of the revision must still be usable by the workspace
+	if restored.DomainID > 0 && restored.DomainID != url.DomainID {
+		domain, result := queries.GetDomainByID(restored.DomainID)
+		if result.Error != nil || domain == nil || !domain.Verified ||
+			(domain.WorkspaceID != nil && url.WorkspaceID != nil && *domain.WorkspaceID != *url.WorkspaceID) {
+			utils.FullyResponse(c, http.StatusConflict, "The domain of this revision is no longer available", utils.ErrResourceExists, nil)
+			return
+		}
+	}
+
+	// The destinations of the revision may have been blocked since
+	current := make(map[string]bool)
+	for _, destination := range url.Destinations() {
+		current[destination] = true
+	}
+	var changed []string
+	for _, destination := range restored.Destinations() {
+		if !current[destination] {
+			changed = append(changed, destination)
+		}
+	}
+	if err := respondUnsafeDestination(c, changed...); err != nil {
+		return
+	}
+	restored = unblockIfSafe(c, restored)
+
+	// Another URL may have taken the slug since
+	if restored.ShortCode != url.ShortCode || restored.DomainID != url.DomainID {
+		taken, err := queries.GetTakenShortCodes(restored.DomainID, []string{restored.ShortCode}, []uint64{url.ID})
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking custom slug", utils.ErrGetData, err)
+			return
+		}
+		if len(taken) > 0 {
+			utils.FullyResponse(c, http.StatusConflict, "The slug of this revision is now used by another URL", utils.ErrResourceExists, nil)
+			return
+		}
+	}
+
+	revisions, changes, err := buildURLRevisions([]models.URL{url}, []models.URL{restored}, getContextUserID(c), models.RevisionSourceRollback, &revision.ID)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking URL revisions", utils.ErrGetData, err)
+		return
+	}
+
+	unblocked := url.BlockedAt != nil && restored.BlockedAt == nil
+	if err := saveUpdatedURL(c, restored, unblocked, revisions); err != nil {
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "URL rolled back successfully", nil, changes)
+}
+
+// getRevisionChanges returns the recorded fields that differ between two states of a URL
+func getRevisionChanges(before, after models.URL) []string {
+	changes := []string{}
+	if before.ShortCode != after.ShortCode {
+		changes = append(changes, models.RevisionFieldShortCode)
+	}
+	if before.OriginalURL != after.OriginalURL {
+		changes = append(changes, models.RevisionFieldOriginalURL)
+	}
+	if before.DomainID != after.DomainID {
+		changes = append(changes, models.RevisionFieldDomainID)
+	}
+	if (before.ExpiresAt == nil) != (after.ExpiresAt == nil) ||
+		(before.ExpiresAt != nil && !before.ExpiresAt.Equal(*after.ExpiresAt)) {
+		changes = append(changes, models.RevisionFieldExpiresAt)
+	}
+	return changes
+}
+
+// newURLRevision builds the revision of a state of a URL
+func newURLRevision(url models.URL, userID *uint64, source string, changes []string, createdAt time.Time) models.URLRevision {
+	return models.URLRevision{
+		ID:            encryption.GenerateID(),
+		URLID:         url.ID,
+		UserID:        userID,
+		Source:        source,
+		ChangedFields: changes,
+		ShortCode:     url.ShortCode,
+		OriginalURL:   url.OriginalURL,
+		DomainID:      url.DomainID,
+		ExpiresAt:     url.ExpiresAt,
+		CreatedAt:     createdAt,
+	}
+}
+
+// buildURLRevisions builds a revision for every URL whose slug, destination, domain or expiry changes, they are
+// stored in the same transaction as the change. A URL without any revision yet first gets one with its previous
+// state, so its original destination is never lost. It returns the revisions to store and those of the changes.
+func buildURLRevisions(before, after []models.URL, userID *uint64, source string, restoredFrom *uint64) ([]models.URLRevision, []models.URLRevision, error) {
+	var ids []uint64
+	for i := range after {
+		if len(getRevisionChanges(before[i], after[i])) > 0 {
+			ids = append(ids, after[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, []models.URLRevision{}, nil
+	}
+
+	withRevisions, err := queries.GetURLIDsWithRevisions(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	var revisions []models.URLRevision
+	changes := []models.URLRevision{}
+	for i := range after {
+		changed := getRevisionChanges(before[i], after[i])
+		if len(changed) == 0 {
+			continue
+		}
+
+		if !withRevisions[after[i].ID] {
+			revisions = append(revisions, newURLRevision(before[i], nil, models.RevisionSourceInitial, []string{}, before[i].UpdatedAt))
+		}
+
+		revision := newURLRevision(after[i], userID, source, changed, now)
+		revision.RestoredFrom = restoredFrom
+		revisions = append(revisions, revision)
+		changes = append(changes, revision)
+	}
+
+	return revisions, changes, nil
+}
+
+// getContextUserID returns the authenticated user of the request, nil for anonymous requests
+func getContextUserID(c *gin.Context) *uint64 {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return nil
+	}
+	id := userID.(uint64)
+	return &id
+}
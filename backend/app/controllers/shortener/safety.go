@@ -0,0 +1,110 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/safety"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// safetyReasonMessages explains each reason a destination is rejected
+var safetyReasonMessages = map[string]string{
+	safety.ReasonInvalid:        "is not a valid URL",
+	safety.ReasonBlockedDomain:  "is on a blocked domain",
+	safety.ReasonBlockedPattern: "matches a blocked pattern",
+	safety.ReasonThreat:         "is a known phishing or malware site",
+	safety.ReasonPrivateAddress: "points to a private network address",
+}
+
+// checkDestinationSafety runs the safety checks on the destinations, empty ones are skipped.
+// It returns the message describing the first rejected destination, empty when all of them are allowed.
+func checkDestinationSafety(ctx context.Context, destinations ...string) (string, *safety.Violation) {
+	for _, destination := range destinations {
+		if destination == "" {
+			continue
+		}
+		if violation := safety.Check(ctx, destination); violation != nil {
+			message, ok := safetyReasonMessages[violation.Reason]
+			if !ok {
+				message = "is not allowed (" + violation.Reason + ")"
+			}
+			return "destination " + destination + " " + message, violation
+		}
+	}
+	return "", nil
+}
+
+// webDeepLinks returns the deep links that are web links, they are redirected to like any destination.
+// Deep links with an app scheme only open the app and aren't checked.
+func webDeepLinks(deepLinks ...string) []string {
+	var links []string
+	for _, deepLink := range deepLinks {
+		if models.IsWebURL(deepLink) {
+			links = append(links, deepLink)
+		}
+	}
+	return links
+}
+
+// checkUpdatedDestinations rejects the unsafe destinations of an update request.
+// The error response has already been sent when an error is returned.
+func checkUpdatedDestinations(c *gin.Context, request *UpdateURLRequest) error {
+	destinations := []string{request.OriginalURL}
+	for _, optional := range []*string{request.PrelaunchURL, request.IOSFallbackURL, request.AndroidFallbackURL, request.OGImageURL} {
+		if optional != nil {
+			destinations = append(destinations, *optional)
+		}
+	}
+	for _, deepLink := range []*string{request.IOSDeepLink, request.AndroidDeepLink} {
+		if deepLink != nil {
+			destinations = append(destinations, webDeepLinks(*deepLink)...)
+		}
+	}
+
+	return respondUnsafeDestination(c, destinations...)
+}
+
+// checkRuleDestination rejects the unsafe destination of a geo, language or schedule rule or of a variant.
+// The error response has already been sent when an error is returned.
+func checkRuleDestination(c *gin.Context, destination string) error {
+	return respondUnsafeDestination(c, destination)
+}
+
+// respondUnsafeDestination sends the error response when one of the destinations is rejected
+func respondUnsafeDestination(c *gin.Context, destinations ...string) error {
+	message, violation := checkDestinationSafety(c.Request.Context(), destinations...)
+	if violation != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, message, utils.ErrBadRequest, gin.H{
+			"reason": violation.Reason,
+		})
+		return errors.New(message)
+	}
+	return nil
+}
+
+// unblockIfSafe lifts the block of an updated URL once all of its destinations pass the checks again
+func unblockIfSafe(c *gin.Context, url models.URL) models.URL {
+	if url.BlockedAt == nil {
+		return url
+	}
+	// The rules and variants redirect visitors too, the block stays while they can't be checked
+	rules, result := queries.GetRuleDestinationsQueue([]uint64{url.ID})
+	if result.Error != nil {
+		return url
+	}
+	destinations := url.Destinations()
+	for _, rule := range rules {
+		destinations = append(destinations, rule.DestinationURL)
+	}
+
+	if _, violation := checkDestinationSafety(c.Request.Context(), destinations...); violation == nil {
+		url.BlockedAt = nil
+		url.BlockedReason = ""
+	}
+	return url
+}
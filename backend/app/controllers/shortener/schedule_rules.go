@@ -0,0 +1,163 @@
+package shortener
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/schedule"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// ScheduleRuleRequest represents the request body for creating or updating a schedule rule
+type ScheduleRuleRequest struct {
+	Days           []string `json:"days" binding:"required,min=1,max=7"`
+	StartTime      string   `json:"start_time" binding:"required"`
+	EndTime        string   `json:"end_time" binding:"required"`
+	Timezone       string   `json:"timezone" binding:"required"`
+	DestinationURL string   `json:"destination_url" binding:"required,url"`
+}
+
+// validateScheduleRuleRequest checks the window of the rule can be evaluated
+func validateScheduleRuleRequest(c *gin.Context, request *ScheduleRuleRequest) error {
+	for i, day := range request.Days {
+		request.Days[i] = strings.ToLower(day)
+	}
+
+	if _, err := schedule.ParseWindow(request.Days, request.StartTime, request.EndTime, request.Timezone); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid schedule window", utils.ErrBadRequest, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetScheduleRules returns all schedule rules of a URL
+func GetScheduleRules(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	rules, result := queries.GetScheduleRulesByURLID(url.ID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving schedule rules", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Schedule rules retrieved successfully", nil, rules)
+}
+
+// CreateScheduleRule adds a time window based destination to a URL
+func CreateScheduleRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	var request ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	if err := validateScheduleRuleRequest(c, &request); err != nil {
+		return
+	}
+
+	rule := models.URLScheduleRule{
+		ID:             encryption.GenerateID(),
+		URLID:          url.ID,
+		Days:           request.Days,
+		StartTime:      request.StartTime,
+		EndTime:        request.EndTime,
+		Timezone:       request.Timezone,
+		DestinationURL: request.DestinationURL,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if result := queries.CreateScheduleRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating schedule rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Schedule rule created successfully", nil, rule)
+}
+
+// UpdateScheduleRule updates the window or destination of a schedule rule
+func UpdateScheduleRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid schedule rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	rule, result := queries.GetScheduleRuleByID(url.ID, ruleID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Schedule rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+	if err := validateScheduleRuleRequest(c, &request); err != nil {
+		return
+	}
+
+	rule.Days = request.Days
+	rule.StartTime = request.StartTime
+	rule.EndTime = request.EndTime
+	rule.Timezone = request.Timezone
+	rule.DestinationURL = request.DestinationURL
+
+	if result := queries.UpdateScheduleRuleQueue(rule); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating schedule rule", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Schedule rule updated successfully", nil, rule)
+}
+
+// DeleteScheduleRule removes a schedule rule from a URL
+func DeleteScheduleRule(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	ruleID, err := utils.StrToUint64(c.Param("ruleID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid schedule rule ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteScheduleRuleQueue(url.ID, ruleID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting schedule rule", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Schedule rule not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Schedule rule deleted successfully", nil, nil)
+}
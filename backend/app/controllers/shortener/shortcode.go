@@ -0,0 +1,62 @@
+package shortener
+
+import (
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/shortcode"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// shortCodeRetries is how often a URL is saved again with a new generated short code,
+// when another URL took the code between the uniqueness check and the insert
+const shortCodeRetries = 3
+
+// shortCodes generates the short codes of URLs created without a custom slug
+var shortCodes = newShortCodeGenerator()
+
+// newShortCodeGenerator creates the generator over the configured alphabet, or the default one when it is invalid
+func newShortCodeGenerator() *shortcode.Generator {
+	generator, err := shortcode.New(utils.ShortCodeAlphabet)
+	if err != nil {
+		logger.Log.Sugar().Warnf("Invalid SHORT_CODE_ALPHABET, using the default alphabet: %v", err)
+		generator, _ = shortcode.New("")
+	}
+	return generator
+}
+
+// getShortCodeLength returns the length of the short codes generated on the domain:
+// the setting of the domain, then the one of the workspace, then the instance default
+func getShortCodeLength(domainID uint64, workspaceID *uint64) int {
+	if domainID > 0 {
+		domain, result := queries.GetDomainByID(domainID)
+		if result.Error == nil && domain.ShortCodeLength > 0 {
+			return domain.ShortCodeLength
+		}
+	}
+
+	if workspaceID != nil {
+		workspace, result := queries.GetWorkspaceQueueByID(*workspaceID)
+		if result.Error == nil && workspace.ShortCodeLength > 0 {
+			return workspace.ShortCodeLength
+		}
+	}
+
+	if shortcode.ValidateLength(utils.ShortCodeLength) == nil {
+		return utils.ShortCodeLength
+	}
+	return shortcode.DefaultLength
+}
+
+// generateShortCode returns a short code no URL of the domain uses. Codes reserved reports as taken,
+// like the ones of earlier rows of an import, are skipped too, reserved may be nil.
+func generateShortCode(domainID uint64, workspaceID *uint64, reserved func(code string) bool) (string, error) {
+	length := getShortCodeLength(domainID, workspaceID)
+
+	// Each domain has its own keyspace, so each one grows on its own
+	return shortCodes.Generate(utils.Uint64ToStr(domainID), length, func(code string) (bool, error) {
+		if reserved != nil && reserved(code) {
+			return true, nil
+		}
+		return queries.CheckShortCodeExistsByDomain(code, domainID)
+	})
+}
@@ -0,0 +1,164 @@
+package shortener
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	db "github.com/yorukot/zipt/pkg/database"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/logger"
+	store "github.com/yorukot/zipt/pkg/s3"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// maxSocialImageSize is the largest social card image accepted, most networks reject larger ones
+const maxSocialImageSize = 5 << 20 // 5 MiB
+
+// socialImageExtensions maps the accepted image types to the extension of their object key
+var socialImageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// socialCardData is the data rendered into the social card page
+type socialCardData struct {
+	Title       string
+	Description string
+	ImageURL    string
+	ShortURL    string
+}
+
+// hasSocialCard reports whether the URL has custom social card metadata
+func hasSocialCard(url models.URL) bool {
+	return url.OGTitle != "" || url.OGDescription != "" || url.OGImageURL != ""
+}
+
+// renderSocialCard serves the Open Graph and Twitter card metadata of the URL to a crawler unfurling it
+func renderSocialCard(c *gin.Context, url models.URL) {
+	var domainName string
+	if url.DomainID > 0 {
+		domain, result := queries.GetDomainByID(url.DomainID)
+		if result.Error == nil && domain.Verified {
+			domainName = domain.Domain
+		}
+	}
+
+	data := socialCardData{
+		Title:       url.OGTitle,
+		Description: url.OGDescription,
+		ImageURL:    url.OGImageURL,
+		ShortURL:    utils.GetFullShortURL(domainName, url.ShortCode),
+	}
+	if data.Title == "" {
+		data.Title = url.Title
+	}
+	if data.Title == "" {
+		data.Title = data.ShortURL
+	}
+
+	renderPage(c, http.StatusOK, "social.html", data)
+}
+
+// UploadSocialImage stores the image of the social card of a URL from a multipart file
+func UploadSocialImage(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	if !store.Enabled() {
+		utils.FullyResponse(c, http.StatusServiceUnavailable, "Image uploads need object storage to be configured", utils.ErrBadRequest, nil)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "An image file is required", utils.ErrBadRequest, nil)
+		return
+	}
+	if header.Size > maxSocialImageSize {
+		utils.FullyResponse(c, http.StatusRequestEntityTooLarge, "The image must not be larger than 5 MiB", utils.ErrBadRequest, nil)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to read image", utils.ErrParse, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSocialImageSize+1))
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to read image", utils.ErrParse, err)
+		return
+	}
+	if len(data) > maxSocialImageSize {
+		utils.FullyResponse(c, http.StatusRequestEntityTooLarge, "The image must not be larger than 5 MiB", utils.ErrBadRequest, nil)
+		return
+	}
+
+	valid, contentType, err := utils.IsValidImageType(data)
+	if !valid {
+		utils.FullyResponse(c, http.StatusBadRequest, "The image must be a JPEG, PNG or GIF", utils.ErrBadRequest, err.Error())
+		return
+	}
+
+	// Every upload gets a new key, so cached cards of the previous image are never served the new one
+	key := "og/" + utils.Uint64ToStr(url.ID) + "/" + utils.Uint64ToStr(encryption.GenerateID()) + "." + socialImageExtensions[contentType]
+	if err := store.PutObject(c.Request.Context(), key, contentType, data); err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to upload image", utils.ErrSaveData, err)
+		return
+	}
+
+	previous := url.OGImageURL
+	url.OGImageURL = store.ObjectURL(key)
+	if result := db.GetDB().Model(&url).UpdateColumn("og_image_url", url.OGImageURL); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating URL", utils.ErrSaveData, result.Error)
+		return
+	}
+	deleteSocialImage(previous)
+
+	utils.FullyResponse(c, http.StatusOK, "Social card image uploaded successfully", nil, gin.H{
+		"og_image_url": url.OGImageURL,
+	})
+}
+
+// DeleteSocialImage removes the image of the social card of a URL
+func DeleteSocialImage(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	if url.OGImageURL == "" {
+		utils.FullyResponse(c, http.StatusNotFound, "The URL has no social card image", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	if result := db.GetDB().Model(&url).UpdateColumn("og_image_url", ""); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating URL", utils.ErrSaveData, result.Error)
+		return
+	}
+	deleteSocialImage(url.OGImageURL)
+
+	utils.FullyResponse(c, http.StatusOK, "Social card image removed successfully", nil, nil)
+}
+
+// deleteSocialImage deletes an uploaded social card image, images hosted elsewhere are left alone.
+// Failures are only logged, the URL no longer references the image.
+func deleteSocialImage(imageURL string) {
+	key, ok := store.ObjectKey(imageURL)
+	if !ok || !strings.HasPrefix(key, "og/") {
+		return
+	}
+	if err := store.DeleteObject(context.Background(), key); err != nil {
+		logger.Log.Sugar().Warnf("Failed to delete social card image %s: %v", key, err)
+	}
+}
@@ -0,0 +1,211 @@
+package shortener
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// TagRequest represents the request body for creating or updating a tag
+type TagRequest struct {
+	Name  string `json:"name" binding:"required,max=64"`
+	Color string `json:"color" binding:"omitempty,hexcolor,max=7"`
+}
+
+// GetTags returns all tags of the workspace
+func GetTags(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	tags, result := queries.GetTagsByWorkspaceID(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving tags", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Tags retrieved successfully", nil, tags)
+}
+
+// CreateTag adds a tag to the workspace
+func CreateTag(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	var request TagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Tag name is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	exists, err := queries.CheckTagNameExists(workspaceID.(uint64), name)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking tag", utils.ErrGetData, err)
+		return
+	}
+	if exists {
+		utils.FullyResponse(c, http.StatusConflict, "A tag with this name already exists", utils.ErrResourceExists, nil)
+		return
+	}
+
+	tag := models.Tag{
+		ID:          encryption.GenerateID(),
+		WorkspaceID: workspaceID.(uint64),
+		Name:        name,
+		Color:       request.Color,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if result := queries.CreateTagQueue(tag); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating tag", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Tag created successfully", nil, tag)
+}
+
+// UpdateTag renames or recolors a tag
+func UpdateTag(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	tagID, err := utils.StrToUint64(c.Param("tagID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid tag ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	tag, result := queries.GetTagByID(workspaceID.(uint64), tagID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Tag not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request TagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		utils.FullyResponse(c, http.StatusBadRequest, "Tag name is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	// Renaming must not collide with another tag
+	if name != tag.Name {
+		exists, err := queries.CheckTagNameExists(tag.WorkspaceID, name)
+		if err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking tag", utils.ErrGetData, err)
+			return
+		}
+		if exists {
+			utils.FullyResponse(c, http.StatusConflict, "A tag with this name already exists", utils.ErrResourceExists, nil)
+			return
+		}
+	}
+
+	tag.Name = name
+	tag.Color = request.Color
+
+	if result := queries.UpdateTagQueue(tag); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating tag", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Tag updated successfully", nil, tag)
+}
+
+// DeleteTag removes a tag from the workspace, its URLs are kept
+func DeleteTag(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	tagID, err := utils.StrToUint64(c.Param("tagID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid tag ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteTagQueue(workspaceID.(uint64), tagID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting tag", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Tag not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Tag deleted successfully", nil, nil)
+}
+
+// getRequestTags loads the workspace tags referenced by a create or update request.
+// The error response has already been sent when an error is returned.
+func getRequestTags(c *gin.Context, tagIDs []string) ([]models.Tag, error) {
+	if len(tagIDs) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		errMsg := "tags can only be used on workspace URLs"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
+	ids := make([]uint64, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		id, err := utils.StrToUint64(tagID)
+		if err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid tag ID", utils.ErrBadRequest, tagID)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	tags, result := queries.GetTagsByIDs(workspaceID.(uint64), ids)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving tags", utils.ErrGetData, result.Error)
+		return nil, result.Error
+	}
+
+	// Every tag must exist in the workspace, duplicates in the request are fine
+	found := make(map[uint64]bool, len(tags))
+	for _, tag := range tags {
+		found[tag.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			errMsg := "tag not found in this workspace"
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, utils.Uint64ToStr(id))
+			return nil, errors.New(errMsg)
+		}
+	}
+
+	return tags, nil
+}
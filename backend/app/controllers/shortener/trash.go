@@ -0,0 +1,129 @@
+package shortener
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// TrashedURL is a URL in the workspace trash
+type TrashedURL struct {
+	ID          uint64    `json:"id,string"`
+	ShortCode   string    `json:"short_code"`
+	OriginalURL string    `json:"original_url"`
+	Title       string    `json:"title,omitempty"`
+	ShortURL    string    `json:"short_url"`
+	DomainID    uint64    `json:"domain_id,omitempty"`
+	DomainName  string    `json:"domain_name,omitempty"`
+	TotalClicks int64     `json:"total_clicks"`
+	CreatedAt   time.Time `json:"created_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgesAt    time.Time `json:"purges_at"` // The URL and its slug are released after this time
+}
+
+// getTrashRetention returns how long trashed URLs are kept before being purged
+func getTrashRetention() time.Duration {
+	return time.Duration(utils.TrashRetentionDays) * 24 * time.Hour
+}
+
+// GetTrashedURLs returns the URLs in the trash of a workspace
+func GetTrashedURLs(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	urls, result := queries.GetTrashedURLsQueue(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving trashed URLs", utils.ErrGetData, result.Error)
+		return
+	}
+
+	trashedURLs := make([]TrashedURL, 0, len(urls))
+	for _, url := range urls {
+		var domainName string
+		if url.Domain != nil && url.Domain.Verified {
+			domainName = url.Domain.Domain
+		}
+		normalizedDomain, _ := utils.NormalizeDomainName(domainName)
+
+		trashedURLs = append(trashedURLs, TrashedURL{
+			ID:          url.ID,
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			Title:       url.Title,
+			ShortURL:    utils.GetFullShortURL(domainName, url.ShortCode),
+			DomainID:    url.DomainID,
+			DomainName:  normalizedDomain,
+			TotalClicks: url.TotalClicks,
+			CreatedAt:   url.CreatedAt,
+			DeletedAt:   url.DeletedAt.Time,
+			PurgesAt:    url.DeletedAt.Time.Add(getTrashRetention()),
+		})
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Trashed URLs retrieved successfully", nil, trashedURLs)
+}
+
+// getTrashedURL loads the trashed URL from the urlID route parameter within the workspace.
+// The error response has already been sent when an error is returned.
+func getTrashedURL(c *gin.Context) (models.URL, error) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return models.URL{}, errors.New("workspace ID is required")
+	}
+
+	urlID, err := utils.StrToUint64(c.Param("urlID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid URL ID", utils.ErrBadRequest, nil)
+		return models.URL{}, err
+	}
+
+	url, result := queries.GetTrashedURLByID(workspaceID.(uint64), urlID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Trashed URL not found", utils.ErrResourceNotFound, nil)
+		return models.URL{}, result.Error
+	}
+
+	return url, nil
+}
+
+// RestoreURL takes a URL out of the trash, it redirects again right away
+func RestoreURL(c *gin.Context) {
+	url, err := getTrashedURL(c)
+	if err != nil {
+		return
+	}
+
+	if result := queries.RestoreURLQueue(url.ID); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error restoring URL", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "URL restored successfully", nil, gin.H{
+		"id":         utils.Uint64ToStr(url.ID),
+		"short_code": url.ShortCode,
+	})
+}
+
+// PurgeURL permanently deletes a trashed URL and releases its slug
+func PurgeURL(c *gin.Context) {
+	url, err := getTrashedURL(c)
+	if err != nil {
+		return
+	}
+
+	if result := queries.PurgeURLQueueByID(url.ID); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error purging URL", utils.ErrDeleteData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "URL permanently deleted", nil, nil)
+}
@@ -3,21 +3,65 @@ package shortener
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yorukot/zipt/app/models"
 	"github.com/yorukot/zipt/app/queries"
-	db "github.com/yorukot/zipt/pkg/database"
 	"github.com/yorukot/zipt/pkg/utils"
 )
 
 // UpdateURLRequest represents the request body for updating a short URL
 type UpdateURLRequest struct {
-	OriginalURL string     `json:"original_url" binding:"omitempty,url"`
-	CustomSlug  string     `json:"custom_slug" binding:"omitempty,max=100"`
-	ExpiresAt   *time.Time `json:"expires_at" binding:"omitempty"`
-	DomainID    *uint64    `json:"domain_id,omitempty"`
+	OriginalURL      string     `json:"original_url" binding:"omitempty,url"`
+	CustomSlug       string     `json:"custom_slug" binding:"omitempty,max=100"`
+	Title            *string    `json:"title,omitempty" binding:"omitempty,max=255"`
+	ExpiresAt        *time.Time `json:"expires_at" binding:"omitempty"`
+	DomainID         *uint64    `json:"domain_id,omitempty"`
+	RedirectType     *int       `json:"redirect_type,omitempty" binding:"omitempty,oneof=301 302 307 308"`
+	Password         *string    `json:"password,omitempty" binding:"omitempty,max=128"` // An empty password removes the protection
+	MaxClicks        *int64     `json:"max_clicks,omitempty" binding:"omitempty,min=0"` // Zero removes the click limit
+	ActivatesAt      *time.Time `json:"activates_at,omitempty" binding:"omitempty"`
+	ClearActivatesAt bool       `json:"clear_activates_at,omitempty"`                // Removes the activation time, the URL is active right away
+	PrelaunchURL     *string    `json:"prelaunch_url,omitempty" binding:"omitempty"` // An empty URL removes the prelaunch destination
+	FolderID         *string    `json:"folder_id,omitempty"`                         // An empty ID removes the URL from its folder
+	TagIDs           *[]string  `json:"tag_ids,omitempty"`                           // Replaces all tags, an empty list removes them
+
+	StickyVariants   *bool   `json:"sticky_variants,omitempty"`
+	ForwardPath      *bool   `json:"forward_path,omitempty"`
+	QueryPassthrough *string `json:"query_passthrough,omitempty" binding:"omitempty,oneof=off replace keep append"`
+
+	// An empty value removes the UTM parameter
+	UTMSource   *string `json:"utm_source,omitempty" binding:"omitempty,max=255"`
+	UTMMedium   *string `json:"utm_medium,omitempty" binding:"omitempty,max=255"`
+	UTMCampaign *string `json:"utm_campaign,omitempty" binding:"omitempty,max=255"`
+	UTMTerm     *string `json:"utm_term,omitempty" binding:"omitempty,max=255"`
+	UTMContent  *string `json:"utm_content,omitempty" binding:"omitempty,max=255"`
+
+	// An empty value removes the platform destination
+	IOSDeepLink        *string `json:"ios_deep_link,omitempty" binding:"omitempty,max=2048"`
+	IOSFallbackURL     *string `json:"ios_fallback_url,omitempty" binding:"omitempty"`
+	AndroidDeepLink    *string `json:"android_deep_link,omitempty" binding:"omitempty,max=2048"`
+	AndroidFallbackURL *string `json:"android_fallback_url,omitempty" binding:"omitempty"`
+
+	// An empty value removes the social card field
+	OGTitle       *string `json:"og_title,omitempty" binding:"omitempty,max=255"`
+	OGDescription *string `json:"og_description,omitempty" binding:"omitempty,max=1024"`
+	OGImageURL    *string `json:"og_image_url,omitempty" binding:"omitempty"`
+}
+
+// hasUpdates reports whether at least one field is being updated
+func (request *UpdateURLRequest) hasUpdates() bool {
+	return request.OriginalURL != "" || request.CustomSlug != "" || request.Title != nil || request.ExpiresAt != nil || request.DomainID != nil ||
+		request.RedirectType != nil || request.Password != nil || request.MaxClicks != nil ||
+		request.ActivatesAt != nil || request.ClearActivatesAt || request.PrelaunchURL != nil || request.StickyVariants != nil ||
+		request.ForwardPath != nil || request.QueryPassthrough != nil ||
+		request.UTMSource != nil || request.UTMMedium != nil || request.UTMCampaign != nil ||
+		request.UTMTerm != nil || request.UTMContent != nil || request.IOSDeepLink != nil || request.IOSFallbackURL != nil ||
+		request.AndroidDeepLink != nil || request.AndroidFallbackURL != nil ||
+		request.FolderID != nil || request.TagIDs != nil ||
+		request.OGTitle != nil || request.OGDescription != nil || request.OGImageURL != nil
 }
 
 // UpdateURL handles updating an existing shortened URL
@@ -71,11 +115,59 @@ func UpdateURL(c *gin.Context) {
 	// Apply updates to the URL model
 	updated := applyUpdates(url, request)
 
+	// The activation window is checked on the updated URL, the request may only change one side of it
+	if errMsg := activationWindowError(updated.ActivatesAt, updated.ExpiresAt, updated.PrelaunchURL); errMsg != "" {
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return
+	}
+
+	// A blocked URL is enabled again once its destinations are safe
+	updated = unblockIfSafe(c, updated)
+
+	// Hash the new password if one was provided
+	if request.Password != nil && *request.Password != "" {
+		if updated.Password, err = hashURLPassword(c, *request.Password); err != nil {
+			return
+		}
+	}
+
+	// Move the URL to another folder
+	if request.FolderID != nil {
+		if updated.FolderID, err = getRequestFolder(c, *request.FolderID); err != nil {
+			return
+		}
+	}
+
+	var tags []models.Tag
+	if request.TagIDs != nil {
+		if tags, err = getRequestTags(c, *request.TagIDs); err != nil {
+			return
+		}
+	}
+
+	// Keep the history of the slug, destination, domain and expiry
+	revisions, _, err := buildURLRevisions([]models.URL{url}, []models.URL{updated}, getContextUserID(c), models.RevisionSourceUpdate, nil)
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error checking URL revisions", utils.ErrGetData, err)
+		return
+	}
+
 	// Save the updated URL
-	if err := saveUpdatedURL(c, updated); err != nil {
+	unblocked := url.BlockedAt != nil && updated.BlockedAt == nil
+	if err := saveUpdatedURL(c, updated, unblocked, revisions); err != nil {
 		return // Error response already sent in saveUpdatedURL
 	}
 
+	if request.TagIDs != nil {
+		if err := queries.ReplaceURLTagsQueue(updated, tags); err != nil {
+			utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating URL tags", utils.ErrSaveData, err)
+			return
+		}
+	} else if tags, result = queries.GetTagsByURLID(updated.ID); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving URL tags", utils.ErrGetData, result.Error)
+		return
+	}
+
 	// Get domain info if a domain ID was provided
 	var domainName string
 	if updated.DomainID > 0 {
@@ -92,13 +184,38 @@ func UpdateURL(c *gin.Context) {
 	normalizedDomain, _ := utils.NormalizeDomainName(domainName)
 
 	response := ShortenURLResponse{
-		ShortCode:   updated.ShortCode,
-		OriginalURL: updated.OriginalURL,
-		ShortURL:    shortURL,
-		DomainID:    updated.DomainID,
-		DomainName:  normalizedDomain,
-		ExpiresAt:   updated.ExpiresAt,
-		CreatedAt:   updated.CreatedAt,
+		ShortCode:          updated.ShortCode,
+		OriginalURL:        updated.OriginalURL,
+		Title:              updated.Title,
+		ShortURL:           shortURL,
+		DomainID:           updated.DomainID,
+		DomainName:         normalizedDomain,
+		RedirectType:       updated.RedirectType,
+		PasswordProtected:  updated.Password != "",
+		ExpiresAt:          updated.ExpiresAt,
+		MaxClicks:          updated.MaxClicks,
+		ActivatesAt:        updated.ActivatesAt,
+		PrelaunchURL:       updated.PrelaunchURL,
+		Status:             getURLStatus(updated, time.Now()),
+		FolderID:           updated.FolderID,
+		Tags:               tags,
+		StickyVariants:     updated.StickyVariants,
+		ForwardPath:        updated.ForwardPath,
+		QueryPassthrough:   updated.QueryPassthrough,
+		UTMSource:          updated.UTMSource,
+		UTMMedium:          updated.UTMMedium,
+		UTMCampaign:        updated.UTMCampaign,
+		UTMTerm:            updated.UTMTerm,
+		UTMContent:         updated.UTMContent,
+		IOSDeepLink:        updated.IOSDeepLink,
+		IOSFallbackURL:     updated.IOSFallbackURL,
+		AndroidDeepLink:    updated.AndroidDeepLink,
+		AndroidFallbackURL: updated.AndroidFallbackURL,
+		OGTitle:            updated.OGTitle,
+		OGDescription:      updated.OGDescription,
+		OGImageURL:         updated.OGImageURL,
+		BlockedReason:      updated.BlockedReason,
+		CreatedAt:          updated.CreatedAt,
 	}
 
 	utils.FullyResponse(c, http.StatusOK, "URL updated successfully", nil, response)
@@ -113,12 +230,58 @@ func validateUpdateRequest(c *gin.Context, urlShortCode string) (*UpdateURLReque
 	}
 
 	// Ensure at least one field is being updated
-	if request.OriginalURL == "" && request.CustomSlug == "" && request.ExpiresAt == nil && request.DomainID == nil {
+	if !request.hasUpdates() {
 		errMsg := "no fields to update were provided"
 		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
 		return nil, errors.New(errMsg)
 	}
 
+	if request.ActivatesAt != nil && request.ClearActivatesAt {
+		errMsg := "activates_at and clear_activates_at cannot be used together"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
+	// Validate the optional destinations, an empty value removes them
+	optionalURLs := []struct {
+		name     string
+		value    *string
+		deepLink bool
+	}{
+		{"prelaunch URL", request.PrelaunchURL, false},
+		{"iOS deep link", request.IOSDeepLink, true},
+		{"iOS fallback URL", request.IOSFallbackURL, false},
+		{"Android deep link", request.AndroidDeepLink, true},
+		{"Android fallback URL", request.AndroidFallbackURL, false},
+		{"social card image URL", request.OGImageURL, false},
+	}
+	for _, optionalURL := range optionalURLs {
+		if optionalURL.value == nil || *optionalURL.value == "" {
+			continue
+		}
+		if (optionalURL.deepLink && !isValidDeepLink(*optionalURL.value)) ||
+			(!optionalURL.deepLink && !isValidHTTPURL(*optionalURL.value)) {
+			errMsg := optionalURL.name + " must be a valid URL"
+			if optionalURL.deepLink {
+				errMsg = optionalURL.name + " must be a web link or an app URI"
+			}
+			utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+			return nil, errors.New(errMsg)
+		}
+	}
+
+	// Reject blocked, malicious and private network destinations
+	if err := checkUpdatedDestinations(c, &request); err != nil {
+		return nil, err
+	}
+
+	// Validate password length if a new password was provided
+	if request.Password != nil && *request.Password != "" && len(*request.Password) < 4 {
+		errMsg := "password must be at least 4 characters long"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return nil, errors.New(errMsg)
+	}
+
 	// Validate custom slug if present
 	if request.CustomSlug != "" && request.CustomSlug != urlShortCode {
 		if !isValidCustomSlug(request.CustomSlug) {
@@ -189,6 +352,10 @@ func applyUpdates(url models.URL, request *UpdateURLRequest) models.URL {
 		url.ShortCode = request.CustomSlug
 	}
 
+	if request.Title != nil {
+		url.Title = *request.Title
+	}
+
 	if request.ExpiresAt != nil {
 		url.ExpiresAt = request.ExpiresAt
 	}
@@ -197,19 +364,129 @@ func applyUpdates(url models.URL, request *UpdateURLRequest) models.URL {
 		url.DomainID = *request.DomainID
 	}
 
+	if request.RedirectType != nil {
+		url.RedirectType = *request.RedirectType
+	}
+
+	if request.ActivatesAt != nil {
+		url.ActivatesAt = request.ActivatesAt
+	}
+
+	if request.ClearActivatesAt {
+		url.ActivatesAt = nil
+	}
+
+	if request.PrelaunchURL != nil {
+		url.PrelaunchURL = *request.PrelaunchURL
+	}
+
+	if request.StickyVariants != nil {
+		url.StickyVariants = *request.StickyVariants
+	}
+
+	if request.ForwardPath != nil {
+		url.ForwardPath = *request.ForwardPath
+	}
+
+	if request.QueryPassthrough != nil {
+		url.QueryPassthrough = *request.QueryPassthrough
+	}
+
+	if request.UTMSource != nil {
+		url.UTMSource = *request.UTMSource
+	}
+
+	if request.UTMMedium != nil {
+		url.UTMMedium = *request.UTMMedium
+	}
+
+	if request.UTMCampaign != nil {
+		url.UTMCampaign = *request.UTMCampaign
+	}
+
+	if request.UTMTerm != nil {
+		url.UTMTerm = *request.UTMTerm
+	}
+
+	if request.UTMContent != nil {
+		url.UTMContent = *request.UTMContent
+	}
+
+	if request.IOSDeepLink != nil {
+		url.IOSDeepLink = *request.IOSDeepLink
+	}
+
+	if request.IOSFallbackURL != nil {
+		url.IOSFallbackURL = *request.IOSFallbackURL
+	}
+
+	if request.AndroidDeepLink != nil {
+		url.AndroidDeepLink = *request.AndroidDeepLink
+	}
+
+	if request.AndroidFallbackURL != nil {
+		url.AndroidFallbackURL = *request.AndroidFallbackURL
+	}
+
+	if request.OGTitle != nil {
+		url.OGTitle = *request.OGTitle
+	}
+
+	if request.OGDescription != nil {
+		url.OGDescription = *request.OGDescription
+	}
+
+	if request.OGImageURL != nil {
+		url.OGImageURL = *request.OGImageURL
+	}
+
+	if request.MaxClicks != nil {
+		if *request.MaxClicks == 0 {
+			url.MaxClicks = nil
+		} else {
+			url.MaxClicks = request.MaxClicks
+		}
+	}
+
+	// The new password is hashed by the caller, an empty one removes the protection
+	if request.Password != nil && *request.Password == "" {
+		url.Password = ""
+	}
+
 	// Always update the UpdatedAt timestamp
 	url.UpdatedAt = time.Now()
 
 	return url
 }
 
-// saveUpdatedURL saves the updated URL to the database
-func saveUpdatedURL(c *gin.Context, url models.URL) error {
-	// Use the database directly since there's no UpdateURL function in queries
-	result := db.GetDB().Save(&url)
-	if result.Error != nil {
-		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating URL", utils.ErrSaveData, result.Error)
-		return result.Error
+// updatedURLColumns are the columns an update or a rollback changes. The click counter and the safety block
+// are written concurrently by redirects and the safety scanner, saving the whole row would put back the
+// values read before the update.
+var updatedURLColumns = []string{
+	"short_code", "original_url", "title", "domain_id", "redirect_type", "password", "activates_at", "prelaunch_url",
+	"expires_at", "max_clicks", "sticky_variants", "forward_path", "query_passthrough",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"ios_deep_link", "ios_fallback_url", "android_deep_link", "android_fallback_url",
+	"og_title", "og_description", "og_image_url", "folder_id", "updated_at",
+}
+
+// saveUpdatedURL saves the updated URL with the revisions of the update, the block is only written when the
+// update lifted it. The error response has already been sent when an error is returned.
+func saveUpdatedURL(c *gin.Context, url models.URL, unblocked bool, revisions []models.URLRevision) error {
+	columns := updatedURLColumns
+	if unblocked {
+		columns = append(slices.Clone(columns), "blocked_at", "blocked_reason")
+	}
+
+	err := queries.SaveURLQueue(url, columns, revisions)
+	if queries.IsShortCodeConflict(err) {
+		errMsg := "custom slug already in use; please choose a different one"
+		utils.FullyResponse(c, http.StatusBadRequest, errMsg, utils.ErrBadRequest, nil)
+		return err
+	}
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating URL", utils.ErrSaveData, err)
+		return err
 	}
 	return nil
 }
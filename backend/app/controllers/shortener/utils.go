@@ -1,6 +1,32 @@
 package shortener
 
-import "regexp"
+import (
+	"errors"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/deeplink"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// reservedShortCodes are the first path segments of the API and server routes, the short URL routes
+// at the root level would otherwise catch their unknown paths
+var reservedShortCodes = map[string]bool{
+	"api":     true,
+	"preview": true,
+	"health":  true,
+}
+
+// IsReservedShortCode reports whether the path segment belongs to the API and server routes
+func IsReservedShortCode(shortCode string) bool {
+	return reservedShortCodes[shortCode]
+}
 
 // isValidCustomSlug checks if a custom slug meets all requirements
 func isValidCustomSlug(slug string) bool {
@@ -9,8 +35,106 @@ func isValidCustomSlug(slug string) bool {
 		return false
 	}
 
+	// The root routes of the server can't be slugs
+	if IsReservedShortCode(slug) {
+		return false
+	}
+
 	// Regex pattern: allow alphanumeric, hyphens, and underscores
 	// Must start and end with an alphanumeric character
 	pattern := regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*[a-zA-Z0-9]$|^[a-zA-Z0-9]$`)
 	return pattern.MatchString(slug)
 }
+
+// hasDotSegment reports whether a forwarded path has . or .. segments, which would move it
+// outside of the path of the destination once joined
+func hasDotSegment(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidLanguageTag checks if a tag looks like a BCP 47 language tag such as en or pt-BR
+func isValidLanguageTag(tag string) bool {
+	pattern := regexp.MustCompile(`^[a-zA-Z]{2,8}(-[a-zA-Z0-9]{1,8})*$`)
+	return pattern.MatchString(tag)
+}
+
+// getURLStatus returns whether the URL is blocked, scheduled, active or expired at the given time
+func getURLStatus(url models.URL, now time.Time) string {
+	if url.BlockedAt != nil {
+		return models.URLStatusBlocked
+	}
+
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(now) {
+		return models.URLStatusExpired
+	}
+
+	if url.MaxClicks != nil && url.TotalClicks >= *url.MaxClicks {
+		return models.URLStatusExpired
+	}
+
+	if url.ActivatesAt != nil && url.ActivatesAt.After(now) {
+		return models.URLStatusScheduled
+	}
+
+	return models.URLStatusActive
+}
+
+// activationWindowError returns why the activation window of a URL is invalid, or an empty string when it is valid
+func activationWindowError(activatesAt, expiresAt *time.Time, prelaunchURL string) string {
+	if activatesAt != nil && expiresAt != nil && !activatesAt.Before(*expiresAt) {
+		return "activation time must be before the expiration time"
+	}
+	if prelaunchURL != "" && activatesAt == nil {
+		return "prelaunch URL requires an activation time"
+	}
+	return ""
+}
+
+// getWorkspaceURL loads the URL from the urlID route parameter and ensures it belongs to the workspace.
+// The error response has already been sent when an error is returned.
+func getWorkspaceURL(c *gin.Context) (models.URL, error) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return models.URL{}, errors.New("workspace ID is required")
+	}
+
+	urlID, err := utils.StrToUint64(c.Param("urlID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid URL ID", utils.ErrBadRequest, nil)
+		return models.URL{}, err
+	}
+
+	url, result := queries.GetURLQueueByID(urlID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Short URL not found", utils.ErrResourceNotFound, nil)
+		return models.URL{}, result.Error
+	}
+
+	if url.WorkspaceID == nil || *url.WorkspaceID != workspaceID.(uint64) {
+		utils.FullyResponse(c, http.StatusForbidden, "URL does not belong to this workspace", utils.ErrForbidden, nil)
+		return models.URL{}, errors.New("url does not belong to this workspace")
+	}
+
+	return url, nil
+}
+
+// isValidHTTPURL checks if the value is an absolute http or https URL
+func isValidHTTPURL(value string) bool {
+	parsed, err := neturl.ParseRequestURI(value)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+// isValidDeepLink checks if the value is a URI with an app scheme, e.g. myapp://product/1, or a universal link.
+// Schemes the browser runs itself, like javascript: and data:, are refused.
+func isValidDeepLink(value string) bool {
+	return deeplink.Valid(value)
+}
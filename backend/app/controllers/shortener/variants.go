@@ -0,0 +1,135 @@
+package shortener
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// VariantRequest represents the request body for creating or updating a variant
+type VariantRequest struct {
+	Name           string `json:"name" binding:"required,max=64"`
+	DestinationURL string `json:"destination_url" binding:"required,url"`
+	Weight         *int   `json:"weight" binding:"required,min=0,max=10000"`
+}
+
+// GetVariants returns all variants of a URL
+func GetVariants(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	variants, result := queries.GetVariantsByURLID(url.ID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error retrieving variants", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Variants retrieved successfully", nil, variants)
+}
+
+// CreateVariant adds a weighted destination to a URL
+func CreateVariant(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	var request VariantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+
+	variant := models.URLVariant{
+		ID:             encryption.GenerateID(),
+		URLID:          url.ID,
+		Name:           request.Name,
+		DestinationURL: request.DestinationURL,
+		Weight:         *request.Weight,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if result := queries.CreateVariantQueue(variant); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error creating variant", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusCreated, "Variant created successfully", nil, variant)
+}
+
+// UpdateVariant updates the name, destination or weight of a variant
+func UpdateVariant(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	variantID, err := utils.StrToUint64(c.Param("variantID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid variant ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	variant, result := queries.GetVariantByID(url.ID, variantID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Variant not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	var request VariantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request", utils.ErrBadRequest, err.Error())
+		return
+	}
+	if err := checkRuleDestination(c, request.DestinationURL); err != nil {
+		return
+	}
+
+	variant.Name = request.Name
+	variant.DestinationURL = request.DestinationURL
+	variant.Weight = *request.Weight
+
+	if result := queries.UpdateVariantQueue(variant); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error updating variant", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Variant updated successfully", nil, variant)
+}
+
+// DeleteVariant removes a variant from a URL
+func DeleteVariant(c *gin.Context) {
+	url, err := getWorkspaceURL(c)
+	if err != nil {
+		return
+	}
+
+	variantID, err := utils.StrToUint64(c.Param("variantID"))
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid variant ID", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteVariantQueue(url.ID, variantID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Error deleting variant", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Variant not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Variant deleted successfully", nil, nil)
+}
@@ -0,0 +1,153 @@
+package workspace
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/encryption"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// getFallbackScope returns the workspace and the optional domain the fallback pages belong to,
+// a domain ID of 0 means the workspace wide pages.
+// The error response has already been sent when an error is returned.
+func getFallbackScope(c *gin.Context) (uint64, uint64, error) {
+	workspaceIDAny, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return 0, 0, errors.New("workspace ID is required")
+	}
+	workspaceID := workspaceIDAny.(uint64)
+
+	domainIDStr := c.Param("domainID")
+	if domainIDStr == "" {
+		return workspaceID, 0, nil
+	}
+
+	domainID, err := utils.StrToUint64(domainIDStr)
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid domain ID", utils.ErrBadRequest, nil)
+		return 0, 0, err
+	}
+
+	domain, result := queries.GetDomainByID(domainID)
+	if result.Error != nil || domain.WorkspaceID == nil || *domain.WorkspaceID != workspaceID {
+		utils.FullyResponse(c, http.StatusNotFound, "Domain not found", utils.ErrResourceNotFound, nil)
+		return 0, 0, errors.New("domain not found")
+	}
+
+	return workspaceID, domainID, nil
+}
+
+// getFallbackKind returns the fallback kind from the route parameter.
+// The error response has already been sent when an error is returned.
+func getFallbackKind(c *gin.Context) (string, error) {
+	kind := c.Param("kind")
+	if !models.IsValidFallbackKind(kind) {
+		utils.FullyResponse(c, http.StatusBadRequest, "Fallback kind must be one of not_found, expired or inactive", utils.ErrBadRequest, nil)
+		return "", errors.New("invalid fallback kind")
+	}
+	return kind, nil
+}
+
+// GetFallbackPages returns the fallback pages of a workspace or domain
+func GetFallbackPages(c *gin.Context) {
+	workspaceID, domainID, err := getFallbackScope(c)
+	if err != nil {
+		return
+	}
+
+	pages, result := queries.GetFallbackPagesQueue(workspaceID, domainID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to get fallback pages", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Fallback pages retrieved successfully", nil, pages)
+}
+
+// UpdateFallbackPage sets the fallback page of a kind for a workspace or domain
+func UpdateFallbackPage(c *gin.Context) {
+	workspaceID, domainID, err := getFallbackScope(c)
+	if err != nil {
+		return
+	}
+
+	kind, err := getFallbackKind(c)
+	if err != nil {
+		return
+	}
+
+	var req FallbackPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request format", utils.ErrBadRequest, err.Error())
+		return
+	}
+
+	if (req.RedirectURL == "") == (req.Template == "") {
+		utils.FullyResponse(c, http.StatusBadRequest, "Either a redirect URL or a template must be provided", utils.ErrBadRequest, nil)
+		return
+	}
+
+	if req.Template != "" {
+		if _, err := template.New(kind).Parse(req.Template); err != nil {
+			utils.FullyResponse(c, http.StatusBadRequest, "Invalid template", utils.ErrBadRequest, err.Error())
+			return
+		}
+	}
+
+	page, result := queries.GetFallbackPageQueue(workspaceID, domainID, kind)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to get fallback page", utils.ErrGetData, result.Error)
+		return
+	}
+
+	if page.ID == 0 {
+		page = models.FallbackPage{
+			ID:          encryption.GenerateID(),
+			WorkspaceID: workspaceID,
+			DomainID:    domainID,
+			Kind:        kind,
+			CreatedAt:   time.Now(),
+		}
+	}
+	page.RedirectURL = req.RedirectURL
+	page.Template = req.Template
+
+	if result := queries.SaveFallbackPageQueue(page); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to save fallback page", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Fallback page saved successfully", nil, page)
+}
+
+// DeleteFallbackPage removes the fallback page of a kind, visitors get the default page again
+func DeleteFallbackPage(c *gin.Context) {
+	workspaceID, domainID, err := getFallbackScope(c)
+	if err != nil {
+		return
+	}
+
+	kind, err := getFallbackKind(c)
+	if err != nil {
+		return
+	}
+
+	result := queries.DeleteFallbackPageQueue(workspaceID, domainID, kind)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to delete fallback page", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Fallback page not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Fallback page deleted successfully", nil, nil)
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // Register the JPEG decoder for logo uploads
+	_ "image/png"  // Register the PNG decoder for logo uploads
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// Workspace logo limits, the logo only covers a small part of a QR code
+const (
+	maxLogoSize      = 256 << 10 // 256 KiB
+	maxLogoDimension = 1024
+)
+
+// logoContentTypes maps the supported image formats to their content type
+var logoContentTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+}
+
+// GetWorkspaceLogo returns the logo image of a workspace
+func GetWorkspaceLogo(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	logo, result := queries.GetWorkspaceLogoQueue(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to get workspace logo", utils.ErrGetData, result.Error)
+		return
+	}
+	if logo.WorkspaceID == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Workspace logo not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
+
+// UpdateWorkspaceLogo sets the logo a workspace can embed in its QR codes, from a PNG or JPEG multipart file
+func UpdateWorkspaceLogo(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "A logo file is required", utils.ErrBadRequest, nil)
+		return
+	}
+	if header.Size > maxLogoSize {
+		utils.FullyResponse(c, http.StatusRequestEntityTooLarge, "The logo must not be larger than 256 KiB", utils.ErrBadRequest, nil)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to read logo", utils.ErrParse, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxLogoSize+1))
+	if err != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to read logo", utils.ErrParse, err)
+		return
+	}
+	if len(data) > maxLogoSize {
+		utils.FullyResponse(c, http.StatusRequestEntityTooLarge, "The logo must not be larger than 256 KiB", utils.ErrBadRequest, nil)
+		return
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	contentType, ok := logoContentTypes[format]
+	if err != nil || !ok {
+		utils.FullyResponse(c, http.StatusBadRequest, "The logo must be a PNG or JPEG image", utils.ErrBadRequest, nil)
+		return
+	}
+	if config.Width > maxLogoDimension || config.Height > maxLogoDimension {
+		utils.FullyResponse(c, http.StatusBadRequest, "The logo must not be larger than 1024x1024 pixels", utils.ErrBadRequest, nil)
+		return
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "The logo must be a PNG or JPEG image", utils.ErrBadRequest, nil)
+		return
+	}
+
+	logo := models.WorkspaceLogo{
+		WorkspaceID: workspaceID.(uint64),
+		ContentType: contentType,
+		Data:        data,
+		Size:        len(data),
+	}
+	if result := queries.SaveWorkspaceLogoQueue(logo); result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to save workspace logo", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Workspace logo saved successfully", nil, logo)
+}
+
+// DeleteWorkspaceLogo removes the logo of a workspace
+func DeleteWorkspaceLogo(c *gin.Context) {
+	workspaceID, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	result := queries.DeleteWorkspaceLogoQueue(workspaceID.(uint64))
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to delete workspace logo", utils.ErrDeleteData, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.FullyResponse(c, http.StatusNotFound, "Workspace logo not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Workspace logo deleted successfully", nil, nil)
+}
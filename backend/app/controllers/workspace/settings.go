@@ -0,0 +1,78 @@
+package workspace
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/shortcode"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// UpdateWorkspaceSettings updates the link defaults of a workspace
+func UpdateWorkspaceSettings(c *gin.Context) {
+	workspaceIDAny, exists := c.Get("workspaceID")
+	if !exists {
+		utils.FullyResponse(c, http.StatusBadRequest, "Workspace ID is required", utils.ErrBadRequest, nil)
+		return
+	}
+
+	workspaceID := workspaceIDAny.(uint64)
+
+	var req WorkspaceSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, "Invalid request format", utils.ErrBadRequest, err.Error())
+		return
+	}
+
+	updates, err := buildSettingsUpdates(req)
+	if err != nil {
+		utils.FullyResponse(c, http.StatusBadRequest, err.Error(), utils.ErrBadRequest, nil)
+		return
+	}
+
+	workspace, result := queries.GetWorkspaceQueueByID(workspaceID)
+	if result.Error != nil {
+		utils.FullyResponse(c, http.StatusNotFound, "Workspace not found", utils.ErrResourceNotFound, nil)
+		return
+	}
+
+	result = queries.UpdateWorkspaceQueue(workspace, updates)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to update workspace settings", utils.ErrSaveData, result.Error)
+		return
+	}
+
+	workspace, result = queries.GetWorkspaceQueueByID(workspaceID)
+	if result.Error != nil {
+		utils.ServerErrorResponse(c, http.StatusInternalServerError, "Failed to get workspace", utils.ErrGetData, result.Error)
+		return
+	}
+
+	utils.FullyResponse(c, http.StatusOK, "Workspace settings updated successfully", nil, workspace)
+}
+
+// buildSettingsUpdates converts the settings request into a column update map
+func buildSettingsUpdates(req WorkspaceSettingsRequest) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+
+	if req.DefaultRedirectType != nil {
+		updates["default_redirect_type"] = *req.DefaultRedirectType
+	}
+
+	if req.ShortCodeLength != nil {
+		if *req.ShortCodeLength != 0 {
+			if err := shortcode.ValidateLength(*req.ShortCodeLength); err != nil {
+				return nil, errors.New("short code " + err.Error())
+			}
+		}
+		updates["short_code_length"] = *req.ShortCodeLength
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.New("no settings to update were provided")
+	}
+
+	return updates, nil
+}
@@ -36,3 +36,16 @@ type InvitationResponse struct {
 type UpdateInvitationRequest struct {
 	Status string `json:"status" binding:"required,oneof=accepted rejected"`
 }
+
+// WorkspaceSettingsRequest represents a request to update workspace level link defaults
+type WorkspaceSettingsRequest struct {
+	DefaultRedirectType *int `json:"default_redirect_type" binding:"omitempty,oneof=301 302 307 308"`
+	ShortCodeLength     *int `json:"short_code_length"` // 0 uses the instance default
+}
+
+// FallbackPageRequest represents a request to set the fallback page of a workspace or domain,
+// exactly one of RedirectURL or Template must be provided
+type FallbackPageRequest struct {
+	RedirectURL string `json:"redirect_url" binding:"omitempty,url"`
+	Template    string `json:"template" binding:"omitempty,max=65536"` // HTML template, see the README for the available fields
+}
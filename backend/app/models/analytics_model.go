@@ -11,6 +11,12 @@ func init() {
 	db.GetDB().AutoMigrate(&URLAnalytics{})
 }
 
+// Click sources, how the visitor reached the short URL
+const (
+	ClickSourceDirect = "direct" // Followed the short URL itself
+	ClickSourceQR     = "qr"     // Scanned one of its QR codes
+)
+
 // ==================================================
 // Analytics time granularities:
 // - 2 mins record for 2 hours
@@ -29,6 +35,10 @@ type URLAnalytics struct {
 	Device     string    `json:"device" gorm:"primaryKey;index;not null"`
 	Browser    string    `json:"browser" gorm:"primaryKey;index;not null"`
 	OS         string    `json:"os" gorm:"primaryKey;index;not null"`
+	GeoRule    string    `json:"geo_rule" gorm:"primaryKey;index;not null;default:fallback"`
+	Variant    string    `json:"variant" gorm:"primaryKey;index;not null;default:none"`
+	Language   string    `json:"language" gorm:"primaryKey;index;not null;default:default"`
+	Source     string    `json:"source" gorm:"primaryKey;index;not null;default:direct"`
 	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;primaryKey;index;not null"`
 	BucketTime time.Time `json:"bucket_time" gorm:"column:bucket_time;not null"`
 }
@@ -47,6 +57,10 @@ type URLAnalyticsTwoMintus struct {
 	Device      string    `json:"device" gorm:"primaryKey;index;not null"`
 	Browser     string    `json:"browser" gorm:"primaryKey;index;not null"`
 	OS          string    `json:"os" gorm:"primaryKey;index;not null"`
+	GeoRule     string    `json:"geo_rule" gorm:"primaryKey;index;not null"`
+	Variant     string    `json:"variant" gorm:"primaryKey;index;not null"`
+	Language    string    `json:"language" gorm:"primaryKey;index;not null"`
+	Source      string    `json:"source" gorm:"primaryKey;index;not null"`
 	Bucket2min  time.Time `json:"bucket_2min" gorm:"column:bucket_2min;primaryKey;index;not null"`
 	TotalClicks int64     `json:"total_clicks" gorm:"column:total_clicks"`
 }
@@ -65,6 +79,10 @@ type URLAnalyticsHourlies struct {
 	Device      string    `json:"device" gorm:"primaryKey;index;not null"`
 	Browser     string    `json:"browser" gorm:"primaryKey;index;not null"`
 	OS          string    `json:"os" gorm:"primaryKey;index;not null"`
+	GeoRule     string    `json:"geo_rule" gorm:"primaryKey;index;not null"`
+	Variant     string    `json:"variant" gorm:"primaryKey;index;not null"`
+	Language    string    `json:"language" gorm:"primaryKey;index;not null"`
+	Source      string    `json:"source" gorm:"primaryKey;index;not null"`
 	BucketHour  time.Time `json:"bucket_hour" gorm:"column:bucket_hour;primaryKey;index;not null"`
 	TotalClicks int64     `json:"total_clicks" gorm:"column:total_clicks"`
 }
@@ -83,6 +101,10 @@ type URLAnalyticsDailies struct {
 	Device      string    `json:"device" gorm:"primaryKey;index;not null"`
 	Browser     string    `json:"browser" gorm:"primaryKey;index;not null"`
 	OS          string    `json:"os" gorm:"primaryKey;index;not null"`
+	GeoRule     string    `json:"geo_rule" gorm:"primaryKey;index;not null"`
+	Variant     string    `json:"variant" gorm:"primaryKey;index;not null"`
+	Language    string    `json:"language" gorm:"primaryKey;index;not null"`
+	Source      string    `json:"source" gorm:"primaryKey;index;not null"`
 	BucketDay   time.Time `json:"bucket_day" gorm:"column:bucket_day;primaryKey;index;not null"`
 	TotalClicks int64     `json:"total_clicks" gorm:"column:total_clicks"`
 }
@@ -101,6 +123,10 @@ type URLAnalyticsMonthlies struct {
 	Device      string    `json:"device" gorm:"primaryKey;index;not null"`
 	Browser     string    `json:"browser" gorm:"primaryKey;index;not null"`
 	OS          string    `json:"os" gorm:"primaryKey;index;not null"`
+	GeoRule     string    `json:"geo_rule" gorm:"primaryKey;index;not null"`
+	Variant     string    `json:"variant" gorm:"primaryKey;index;not null"`
+	Language    string    `json:"language" gorm:"primaryKey;index;not null"`
+	Source      string    `json:"source" gorm:"primaryKey;index;not null"`
 	BucketMonth time.Time `json:"bucket_month" gorm:"column:bucket_month;primaryKey;index;not null"`
 	TotalClicks int64     `json:"total_clicks" gorm:"column:total_clicks"`
 }
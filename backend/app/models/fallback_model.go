@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&FallbackPage{})
+}
+
+// Fallback kinds, the reason a short URL can't be followed
+const (
+	FallbackNotFound = "not_found"
+	FallbackExpired  = "expired"
+	FallbackInactive = "inactive"
+)
+
+// IsValidFallbackKind checks if the given kind is a supported fallback kind
+func IsValidFallbackKind(kind string) bool {
+	switch kind {
+	case FallbackNotFound, FallbackExpired, FallbackInactive:
+		return true
+	}
+	return false
+}
+
+// FallbackPage is shown to browsers instead of the JSON error when a short URL can't be followed.
+// It either redirects to RedirectURL or renders Template as an HTML template.
+type FallbackPage struct {
+	ID          uint64    `json:"id,string" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id,string" gorm:"not null;uniqueIndex:idx_fallback_page"`
+	DomainID    uint64    `json:"domain_id,string" gorm:"not null;default:0;uniqueIndex:idx_fallback_page"` // 0 applies to every domain of the workspace
+	Kind        string    `json:"kind" gorm:"size:16;not null;uniqueIndex:idx_fallback_page"`
+	RedirectURL string    `json:"redirect_url,omitempty"`
+	Template    string    `json:"template,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+
+	Workspace Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
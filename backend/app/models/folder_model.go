@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&Folder{})
+}
+
+// Folder groups URLs of a workspace, folders can be nested under a single parent
+type Folder struct {
+	ID          uint64    `json:"id,string" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id,string" gorm:"not null;index"`
+	ParentID    *uint64   `json:"parent_id,string,omitempty" gorm:"index"` // Nil for top level folders
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+
+	Workspace Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	Parent    *Folder   `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLGeoRule{})
+}
+
+// GeoRuleFallback is recorded in analytics when no geo rule matched the click
+const GeoRuleFallback = "fallback"
+
+// URLGeoRule redirects clicks from a country to an alternate destination
+type URLGeoRule struct {
+	ID             uint64    `json:"id,string" gorm:"primaryKey"`
+	URLID          uint64    `json:"url_id,string" gorm:"column:url_id;not null;uniqueIndex:idx_url_geo_rule"`
+	CountryCode    string    `json:"country_code" gorm:"size:2;not null;uniqueIndex:idx_url_geo_rule"` // ISO 3166-1 alpha-2
+	DestinationURL string    `json:"destination_url" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null"`
+
+	URL URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
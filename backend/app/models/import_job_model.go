@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&ImportJob{})
+}
+
+// Import modes
+const (
+	ImportModeAtomic  = "atomic"  // Nothing is created unless every row is valid
+	ImportModePartial = "partial" // Valid rows are created, invalid ones are reported
+)
+
+// Import job statuses
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// Import row statuses
+const (
+	ImportRowCreated = "created"
+	ImportRowFailed  = "failed"
+	ImportRowSkipped = "skipped" // Valid, but not created because another row of an atomic import failed
+)
+
+// ImportJob tracks a bulk creation of URLs, large imports run in the background and are polled
+type ImportJob struct {
+	ID          uint64            `json:"id,string" gorm:"primaryKey"`
+	WorkspaceID uint64            `json:"workspace_id,string" gorm:"not null;index"`
+	UserID      uint64            `json:"user_id,string" gorm:"not null"`
+	Mode        string            `json:"mode" gorm:"size:16;not null"`
+	Status      string            `json:"status" gorm:"size:16;not null"`
+	TotalRows   int               `json:"total_rows" gorm:"not null"`
+	CreatedRows int               `json:"created_rows" gorm:"not null;default:0"`
+	FailedRows  int               `json:"failed_rows" gorm:"not null;default:0"`
+	Rows        []ImportRowResult `json:"rows" gorm:"serializer:json"` // Per row report, filled once the job finished
+	Error       string            `json:"error,omitempty"`             // Set when the whole job failed
+	CreatedAt   time.Time         `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"not null"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+
+	Workspace Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// ImportRowResult reports the outcome of a single import row
+type ImportRowResult struct {
+	Row       int    `json:"row"` // 1-based, the CSV header doesn't count
+	Status    string `json:"status"`
+	URLID     uint64 `json:"url_id,string,omitempty"`
+	ShortCode string `json:"short_code,omitempty"`
+	ShortURL  string `json:"short_url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
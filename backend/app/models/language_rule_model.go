@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLLanguageRule{})
+}
+
+// LanguageDefault is recorded in analytics when no language rule matched the click
+const LanguageDefault = "default"
+
+// URLLanguageRule redirects clicks preferring a language to an alternate destination
+type URLLanguageRule struct {
+	ID             uint64    `json:"id,string" gorm:"primaryKey"`
+	URLID          uint64    `json:"url_id,string" gorm:"column:url_id;not null;uniqueIndex:idx_url_language_rule"`
+	Language       string    `json:"language" gorm:"size:35;not null;uniqueIndex:idx_url_language_rule"` // Lowercase BCP 47 tag, e.g. en or pt-br
+	DestinationURL string    `json:"destination_url" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null"`
+
+	URL URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLScheduleRule{})
+}
+
+// URLScheduleRule redirects clicks inside a recurring weekly time window to an alternate destination
+type URLScheduleRule struct {
+	ID             uint64    `json:"id,string" gorm:"primaryKey"`
+	URLID          uint64    `json:"url_id,string" gorm:"column:url_id;not null;index"`
+	Days           []string  `json:"days" gorm:"serializer:json;not null"` // mon, tue, wed, thu, fri, sat, sun
+	StartTime      string    `json:"start_time" gorm:"size:5;not null"`    // HH:MM
+	EndTime        string    `json:"end_time" gorm:"size:5;not null"`      // HH:MM, before StartTime for overnight windows
+	Timezone       string    `json:"timezone" gorm:"size:64;not null"`     // IANA timezone name
+	DestinationURL string    `json:"destination_url" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null"`
+
+	URL URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&Tag{})
+}
+
+// Tag labels URLs of a workspace, a URL can have many tags
+type Tag struct {
+	ID          uint64    `json:"id,string" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id,string" gorm:"not null;uniqueIndex:idx_workspace_tag"`
+	Name        string    `json:"name" gorm:"size:64;not null;uniqueIndex:idx_workspace_tag"`
+	Color       string    `json:"color,omitempty" gorm:"size:7"` // Hex color, e.g. #1e88e5
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+
+	Workspace Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
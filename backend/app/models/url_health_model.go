@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLHealth{})
+}
+
+// Health states of a URL destination
+const (
+	URLHealthUnknown = "unknown" // Not checked yet, or the destination changed since
+	URLHealthHealthy = "healthy"
+	URLHealthBroken  = "broken" // The destination didn't respond, is gone or fails on the server side
+)
+
+// URLHealth is the result of the last check of the destination of a URL by the link monitor
+type URLHealth struct {
+	URLID       uint64    `json:"-" gorm:"primaryKey"`
+	Destination string    `json:"destination" gorm:"not null"` // The checked destination, a changed destination is checked again
+	Status      string    `json:"status" gorm:"size:16;not null;index"`
+	StatusCode  int       `json:"status_code,omitempty"` // 0 when the destination didn't respond
+	LatencyMs   int64     `json:"latency_ms"`
+	Error       string    `json:"error,omitempty" gorm:"size:255"`
+	Failures    int       `json:"consecutive_failures" gorm:"not null;default:0"` // Broken destinations are checked less often
+	CheckedAt   time.Time `json:"last_checked_at" gorm:"not null"`
+	NextCheckAt time.Time `json:"-" gorm:"not null;index"`
+
+	URL *URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
@@ -1,38 +1,177 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	db "github.com/yorukot/zipt/pkg/database"
+	"github.com/yorukot/zipt/pkg/logger"
+	"gorm.io/gorm"
 )
 
 func init() {
 	db.GetDB().AutoMigrate(&URL{})
 	db.GetDB().AutoMigrate(&Domain{})
+	createURLListIndexes()
+	createShortCodeIndex()
 }
 
+// ShortCodeIndex keeps the short codes of a domain unique. It covers deleted URLs too, their slug stays
+// reserved until they are purged.
+const ShortCodeIndex = "idx_urls_domain_short_code"
+
+// createShortCodeIndex creates the unique short code index, it fails while a domain has duplicate short codes
+func createShortCodeIndex() {
+	statement := "CREATE UNIQUE INDEX IF NOT EXISTS " + ShortCodeIndex + " ON urls (domain_id, short_code)"
+	if err := db.GetDB().Exec(statement).Error; err != nil {
+		logger.Log.Sugar().Warnf("Failed to create the unique short code index, remove the duplicate short codes first: %v", err)
+	}
+}
+
+// urlListIndexes keep the workspace URL list fast with many URLs: trigram indexes back the
+// substring search and the composite indexes back each sort order of the keyset pagination
+var urlListIndexes = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"CREATE INDEX IF NOT EXISTS idx_urls_short_code_trgm ON urls USING gin (short_code gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_urls_original_url_trgm ON urls USING gin (original_url gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_urls_workspace_created ON urls (workspace_id, created_at, id)",
+	"CREATE INDEX IF NOT EXISTS idx_urls_workspace_updated ON urls (workspace_id, updated_at, id)",
+	"CREATE INDEX IF NOT EXISTS idx_urls_workspace_clicks ON urls (workspace_id, total_clicks, id)",
+}
+
+// createURLListIndexes creates the indexes GORM can't express with struct tags
+func createURLListIndexes() {
+	for _, statement := range urlListIndexes {
+		if err := db.GetDB().Exec(statement).Error; err != nil {
+			logger.Log.Sugar().Warnf("Failed to create URL list index: %v", err)
+		}
+	}
+}
+
+// Redirect types (HTTP status codes used when redirecting a short URL)
+const (
+	RedirectTypeMovedPermanently  = 301
+	RedirectTypeFound             = 302
+	RedirectTypeTemporaryRedirect = 307
+	RedirectTypePermanentRedirect = 308
+)
+
+// DefaultRedirectType is used for editable links, it is not cached by browsers
+// so every click still reaches the server and gets tracked
+const DefaultRedirectType = RedirectTypeFound
+
+// IsValidRedirectType checks if the given status code is a supported redirect type
+func IsValidRedirectType(redirectType int) bool {
+	switch redirectType {
+	case RedirectTypeMovedPermanently, RedirectTypeFound, RedirectTypeTemporaryRedirect, RedirectTypePermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// Query passthrough modes, deciding what happens when a query parameter of the visitor
+// already exists on the destination
+const (
+	QueryPassthroughOff     = "off"     // The query string of the visitor is dropped
+	QueryPassthroughReplace = "replace" // The value of the visitor replaces the destination value
+	QueryPassthroughKeep    = "keep"    // The destination value is kept
+	QueryPassthroughAppend  = "append"  // Both values are kept
+)
+
+// URL statuses, derived from the activation time, expiration time and click limit
+const (
+	URLStatusScheduled = "scheduled"
+	URLStatusActive    = "active"
+	URLStatusExpired   = "expired"
+	URLStatusBlocked   = "blocked" // Disabled by the destination safety checks
+)
+
 // URL represents a shortened URL in the database
 type URL struct {
-	ID          uint64     `json:"id" gorm:"primary_key"`
-	DomainID    uint64     `json:"domain_id,omitempty" gorm:"index;default:0"`
-	WorkspaceID *uint64    `json:"workspace_id,omitempty" gorm:"index"`
-	OriginalURL string     `json:"original_url" gorm:"not null"`
-	ShortCode   string     `json:"short_code" gorm:"not null"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null"`
-	TotalClicks int64      `json:"total_clicks" gorm:"default:0"`
-	Domain      *Domain    `json:"domain,omitempty" gorm:"foreignKey:DomainID;references:ID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE"`
+	ID           uint64     `json:"id" gorm:"primary_key"`
+	DomainID     uint64     `json:"domain_id,omitempty" gorm:"index;default:0"`
+	WorkspaceID  *uint64    `json:"workspace_id,omitempty" gorm:"index"`
+	OriginalURL  string     `json:"original_url" gorm:"not null"`
+	ShortCode    string     `json:"short_code" gorm:"not null"`
+	Title        string     `json:"title,omitempty" gorm:"size:255"`
+	RedirectType int        `json:"redirect_type" gorm:"not null;default:302"`
+	Password     string     `json:"-"`                       // Hashed password, empty if the URL is not protected
+	ActivatesAt  *time.Time `json:"activates_at,omitempty"`  // The URL doesn't redirect before this time
+	PrelaunchURL string     `json:"prelaunch_url,omitempty"` // Optional destination used before ActivatesAt
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	MaxClicks    *int64     `json:"max_clicks,omitempty"` // The URL stops working once TotalClicks reaches it
+
+	StickyVariants   bool   `json:"sticky_variants" gorm:"default:false"`                  // Returning visitors keep seeing the same variant
+	ForwardPath      bool   `json:"forward_path" gorm:"default:false"`                     // Extra path segments and the query string are appended to the destination
+	QueryPassthrough string `json:"query_passthrough" gorm:"size:16;not null;default:off"` // How the query string of the visitor is merged into the destination
+
+	// UTM parameters composed into the destination at redirect time, so OriginalURL stays clean
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	UTMTerm     string `json:"utm_term,omitempty"`
+	UTMContent  string `json:"utm_content,omitempty"`
+
+	// Platform destinations, the deep link opens the app and the fallback is usually the store page
+	IOSDeepLink        string `json:"ios_deep_link,omitempty"`
+	IOSFallbackURL     string `json:"ios_fallback_url,omitempty"`
+	AndroidDeepLink    string `json:"android_deep_link,omitempty"`
+	AndroidFallbackURL string `json:"android_fallback_url,omitempty"`
+
+	// Card shown when the link is shared in chat apps and social networks, instead of whatever the destination offers
+	OGTitle       string `json:"og_title,omitempty" gorm:"size:255"`
+	OGDescription string `json:"og_description,omitempty" gorm:"size:1024"`
+	OGImageURL    string `json:"og_image_url,omitempty"`
+
+	// Set when a destination matches the safety blocklists, a blocked URL doesn't redirect
+	BlockedAt     *time.Time `json:"blocked_at,omitempty"`
+	BlockedReason string     `json:"blocked_reason,omitempty" gorm:"size:64"`
+
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+	TotalClicks int64     `json:"total_clicks" gorm:"default:0"`
+	Domain      *Domain   `json:"domain,omitempty" gorm:"foreignKey:DomainID;references:ID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE"`
+
+	// Organization within the workspace, removing a folder or tag never deletes its URLs
+	FolderID *uint64 `json:"folder_id,string,omitempty" gorm:"index"`
+	Folder   *Folder `json:"-" gorm:"foreignKey:FolderID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE"`
+	Tags     []Tag   `json:"tags,omitempty" gorm:"many2many:url_tags;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+
+	// Last check of the destination by the link monitor, nil until it is checked
+	Health *URLHealth `json:"health,omitempty" gorm:"foreignKey:URLID"`
+
+	// Deleted URLs stay in the workspace trash, keeping their slug reserved until they are purged
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Destinations returns the web addresses stored on the URL that visitors and crawlers are sent to: the
+// original and prelaunch URLs, the platform fallbacks, deep links that are web links and the social card
+// image. Empty values are included. Rule and variant destinations are stored with their rules.
+func (url URL) Destinations() []string {
+	destinations := []string{url.OriginalURL, url.PrelaunchURL, url.IOSFallbackURL, url.AndroidFallbackURL, url.OGImageURL}
+	for _, deepLink := range []string{url.IOSDeepLink, url.AndroidDeepLink} {
+		if IsWebURL(deepLink) {
+			destinations = append(destinations, deepLink)
+		}
+	}
+	return destinations
+}
+
+// IsWebURL reports whether the value is an http or https URL, deep links with an app scheme aren't
+func IsWebURL(value string) bool {
+	lower := strings.ToLower(value)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
 }
 
 type Domain struct {
-	ID          uint64     `json:"id" gorm:"primary_key"`
-	WorkspaceID *uint64    `json:"workspace_id,omitempty" gorm:"index"`
-	Domain      string     `json:"domain" gorm:"not null"`
-	Verified    bool       `json:"verified" gorm:"default:false"`
-	VerifyToken string     `json:"verify_token" gorm:"not null"`
-	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null"`
-	URLs        []URL      `json:"-" gorm:"foreignKey:DomainID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	ID              uint64     `json:"id" gorm:"primary_key"`
+	WorkspaceID     *uint64    `json:"workspace_id,omitempty" gorm:"index"`
+	Domain          string     `json:"domain" gorm:"not null"`
+	Verified        bool       `json:"verified" gorm:"default:false"`
+	VerifyToken     string     `json:"verify_token" gorm:"not null"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	ShortCodeLength int        `json:"short_code_length,omitempty" gorm:"not null;default:0"` // Length of generated short codes on the domain, 0 for the workspace setting
+	CreatedAt       time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"not null"`
+	URLs            []URL      `json:"-" gorm:"foreignKey:DomainID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
 }
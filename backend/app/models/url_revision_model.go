@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLRevision{})
+}
+
+// Revision sources, what produced a revision
+const (
+	RevisionSourceInitial  = "initial"  // The state of the URL before its first recorded change
+	RevisionSourceUpdate   = "update"   // An edit of the URL
+	RevisionSourceBulk     = "bulk"     // A bulk operation on many URLs
+	RevisionSourceRollback = "rollback" // A rollback to an earlier revision
+)
+
+// Revision fields, the URL fields whose changes are recorded
+const (
+	RevisionFieldShortCode   = "short_code"
+	RevisionFieldOriginalURL = "original_url"
+	RevisionFieldDomainID    = "domain_id"
+	RevisionFieldExpiresAt   = "expires_at"
+)
+
+// URLRevision is a snapshot of the slug, destination, domain and expiry of a URL after a change
+type URLRevision struct {
+	ID            uint64     `json:"id,string" gorm:"primaryKey"`
+	URLID         uint64     `json:"url_id,string" gorm:"column:url_id;not null;index:idx_url_revision,priority:1"`
+	UserID        *uint64    `json:"user_id,string,omitempty"` // Nil when nobody is known to have made the change
+	Source        string     `json:"source" gorm:"size:16;not null"`
+	ChangedFields []string   `json:"changed_fields" gorm:"serializer:json"`
+	ShortCode     string     `json:"short_code" gorm:"not null"`
+	OriginalURL   string     `json:"original_url" gorm:"not null"`
+	DomainID      uint64     `json:"domain_id,string" gorm:"not null;default:0"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RestoredFrom  *uint64    `json:"restored_from,string,omitempty"` // The revision a rollback went back to
+	CreatedAt     time.Time  `json:"created_at" gorm:"not null;index:idx_url_revision,priority:2"`
+
+	URL URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
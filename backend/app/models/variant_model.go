@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&URLVariant{})
+}
+
+// VariantNone is recorded in analytics when no variant served the click
+const VariantNone = "none"
+
+// URLVariant is one of the weighted destinations rotated behind a URL
+type URLVariant struct {
+	ID             uint64    `json:"id,string" gorm:"primaryKey"`
+	URLID          uint64    `json:"url_id,string" gorm:"column:url_id;not null;index"`
+	Name           string    `json:"name" gorm:"size:64;not null"`
+	DestinationURL string    `json:"destination_url" gorm:"not null"`
+	Weight         int       `json:"weight" gorm:"not null;default:1"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null"`
+
+	URL URL `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	db "github.com/yorukot/zipt/pkg/database"
+)
+
+func init() {
+	db.GetDB().AutoMigrate(&WorkspaceLogo{})
+}
+
+// WorkspaceLogo is the image a workspace can embed in the center of its QR codes
+type WorkspaceLogo struct {
+	WorkspaceID uint64    `json:"workspace_id,string" gorm:"primaryKey"`
+	ContentType string    `json:"content_type" gorm:"size:32;not null"`
+	Data        []byte    `json:"-" gorm:"not null"`
+	Size        int       `json:"size" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+
+	Workspace Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
@@ -25,10 +25,12 @@ const (
 
 // Users data type / table
 type Workspace struct {
-	ID        uint64    `json:"id,string" gorm:"primaryKey"`
-	Name      string    `json:"name" binding:"required"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                  uint64    `json:"id,string" gorm:"primaryKey"`
+	Name                string    `json:"name" binding:"required"`
+	DefaultRedirectType int       `json:"default_redirect_type" gorm:"not null;default:302"`
+	ShortCodeLength     int       `json:"short_code_length,omitempty" gorm:"not null;default:0"` // Length of generated short codes, 0 for the instance default
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 type WorkspaceUser struct {
@@ -12,7 +12,8 @@ import (
 )
 
 // For the add or update analytics data to the database
-func TrackAllAnalytics(tracker models.URLAnalytics) *gorm.DB {
+// countClick is false when the click was already counted by ClaimURLClick
+func TrackAllAnalytics(tracker models.URLAnalytics, countClick bool) *gorm.DB {
 	now := time.Now()
 
 	analytics := models.URLAnalytics{
@@ -23,6 +24,10 @@ func TrackAllAnalytics(tracker models.URLAnalytics) *gorm.DB {
 		Device:    tracker.Device,
 		Browser:   tracker.Browser,
 		OS:        tracker.OS,
+		GeoRule:   tracker.GeoRule,
+		Variant:   tracker.Variant,
+		Language:  tracker.Language,
+		Source:    tracker.Source,
 		CreatedAt: now,
 	}
 
@@ -33,6 +38,10 @@ func TrackAllAnalytics(tracker models.URLAnalytics) *gorm.DB {
 		logger.Log.Error(fmt.Sprintf("Error tracking analytics: %v", result.Error))
 	}
 
+	if !countClick {
+		return result
+	}
+
 	// Update the URL total click count
 	result = db.GetDB().Model(&models.URL{}).Where("id = ?", analytics.URLID).Update("total_clicks", gorm.Expr("total_clicks + ?", 1))
 
@@ -54,37 +63,6 @@ func GetTotalClicks(urlID uint64) (int64, error) {
 	return url.TotalClicks, nil
 }
 
-// GetURLsByWorkspaceID retrieves all URLs for a specified workspace ID
-func GetURLsByWorkspaceID(workspaceID uint64) ([]models.URL, error) {
-	var urls []models.URL
-
-	// Use a more explicit join for preloading domains
-	result := db.GetDB().
-		Joins("LEFT JOIN domains ON urls.domain_id = domains.id").
-		Preload("Domain").
-		Where("urls.workspace_id = ?", workspaceID).
-		Order("urls.created_at DESC").
-		Find(&urls)
-
-	if result.Error != nil {
-		logger.Log.Error(fmt.Sprintf("Error retrieving URLs for workspace: %v", result.Error))
-		return nil, result.Error
-	}
-
-	// Add fallback for domain ID 0 which might not be properly preloaded
-	for i := range urls {
-		if urls[i].Domain == nil && urls[i].DomainID == 0 {
-			// Try to get the default domain (ID 0)
-			var domain models.Domain
-			if err := db.GetDB().First(&domain, 0).Error; err == nil {
-				urls[i].Domain = &domain
-			}
-		}
-	}
-
-	return urls, nil
-}
-
 type TimeAccuracy string
 
 const (
@@ -122,7 +100,8 @@ type AnalyticsDataPoint struct {
 }
 
 // GetDiffrentTypeAnalyticsData retrieves analytics data for a specific URL by dataType (country, referrer, etc.)
-func GetDiffrentTypeAnalyticsData(urlID uint64, page int, timeAccuracy TimeAccuracy, dataType string, startDate time.Time, endDate time.Time) ([]AnalyticsDataPoint, error) {
+// with optional filters, e.g. to break down a single variant
+func GetDiffrentTypeAnalyticsData(urlID uint64, page int, timeAccuracy TimeAccuracy, dataType string, filters map[string]string, startDate time.Time, endDate time.Time) ([]AnalyticsDataPoint, error) {
 	var analyticsData []AnalyticsDataPoint
 
 	// If timeAccuracy is not provided, determine based on date range
@@ -166,10 +145,32 @@ func GetDiffrentTypeAnalyticsData(urlID uint64, page int, timeAccuracy TimeAccur
 		validDataType = "browser"
 	case "os":
 		validDataType = "os"
+	case "geo_rule":
+		validDataType = "geo_rule"
+	case "variant":
+		validDataType = "variant"
+	case "language":
+		validDataType = "language"
+	case "source":
+		validDataType = "source"
 	default:
 		return nil, fmt.Errorf("invalid data type: %s", dataType)
 	}
 
+	// Add filters if provided
+	args := []interface{}{urlID, startDate, endDate}
+	filterClause := ""
+	for field, value := range filters {
+		if value != "" {
+			// Validate field to prevent SQL injection
+			switch field {
+			case "referrer", "country", "city", "device", "browser", "os", "geo_rule", "variant", "language", "source":
+				filterClause += fmt.Sprintf(" AND %s = ?", field)
+				args = append(args, value)
+			}
+		}
+	}
+
 	// Build and execute the query to get top 10 values by click count
 	query := fmt.Sprintf(`SELECT 
 		%s AS value,
@@ -178,13 +179,13 @@ func GetDiffrentTypeAnalyticsData(urlID uint64, page int, timeAccuracy TimeAccur
 		%s
 	WHERE 
 		url_id = ?
-		AND %s BETWEEN ? AND ?
+		AND %s BETWEEN ? AND ?%s
 	GROUP BY 
 		%s
 	ORDER BY 
 		total_clicks DESC
 	LIMIT 10 OFFSET ?`,
-		validDataType, tableName, timeField, validDataType)
+		validDataType, tableName, timeField, filterClause, validDataType)
 
 	// Calculate offset based on page number (0-indexed)
 	offset := (page - 1) * 10
@@ -192,7 +193,7 @@ func GetDiffrentTypeAnalyticsData(urlID uint64, page int, timeAccuracy TimeAccur
 		offset = 0
 	}
 
-	args := []interface{}{urlID, startDate, endDate, offset}
+	args = append(args, offset)
 	logger.Log.Sugar().Debugf("Analytics query: %s with args: %v", query, args)
 
 	// Execute the query
@@ -258,7 +259,7 @@ func GetTimeSeriesData(urlID uint64, timeAccuracy TimeAccuracy, filters map[stri
 			if value != "" {
 				// Validate field to prevent SQL injection
 				switch field {
-				case "referrer", "country", "city", "device", "browser", "os":
+				case "referrer", "country", "city", "device", "browser", "os", "geo_rule", "variant", "language", "source":
 					query += fmt.Sprintf(" AND %s = ?", field)
 					args = append(args, value)
 				}
@@ -0,0 +1,117 @@
+package queries
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBulkConflict is returned when URLs no longer pass the checks of a bulk operation once it is applied
+var ErrBulkConflict = errors.New("the bulk operation fails for URLs changed in the meantime")
+
+// GetWorkspaceURLsByIDs retrieves the URLs of a workspace among the given IDs
+func GetWorkspaceURLsByIDs(workspaceID uint64, ids []uint64) ([]models.URL, *gorm.DB) {
+	var urls []models.URL
+	result := db.GetDB().Where("workspace_id = ? AND id IN ?", workspaceID, ids).Order("id").Find(&urls)
+	return urls, result
+}
+
+// GetWorkspaceURLsByFilter retrieves at most limit URLs of a workspace matching the list filters
+func GetWorkspaceURLsByFilter(workspaceID uint64, filter URLFilter, limit int) ([]models.URL, *gorm.DB) {
+	var urls []models.URL
+	result := filterURLsQuery(db.GetDB().Model(&models.URL{}).Where("urls.workspace_id = ?", workspaceID), filter).
+		Order("urls.id").
+		Limit(limit).
+		Find(&urls)
+	return urls, result
+}
+
+// GetTakenShortCodes returns which of the short codes are used on the domain by URLs other than the excluded ones.
+// Trashed URLs keep their short code reserved.
+func GetTakenShortCodes(domainID uint64, shortCodes []string, excludeIDs []uint64) ([]string, error) {
+	var taken []string
+	result := db.GetDB().Unscoped().Model(&models.URL{}).
+		Where("domain_id = ? AND short_code IN ? AND id NOT IN ?", domainID, shortCodes, excludeIDs).
+		Distinct().
+		Pluck("short_code", &taken)
+	return taken, result.Error
+}
+
+// BulkUpdateURLsQueue applies the same column updates to all URLs in one statement, the revisions of the
+// change are stored in the same transaction
+func BulkUpdateURLsQueue(ids []uint64, updates map[string]interface{}, revisions []models.URLRevision) error {
+	updates["updated_at"] = time.Now()
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.URL{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+		return createURLRevisions(tx, revisions)
+	})
+}
+
+// BulkSetURLExpiryQueue sets the expiry of all URLs, nil removes it. The URLs are locked and checked again in the
+// same transaction: the IDs of the URLs activating at or after the expiry are returned with ErrBulkConflict,
+// and nothing is changed. The revisions of the change are stored in the same transaction.
+func BulkSetURLExpiryQueue(ids []uint64, expiresAt *time.Time, revisions []models.URLRevision) ([]uint64, error) {
+	var conflicts []uint64
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var urls []models.URL
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "activates_at").Where("id IN ?", ids).Find(&urls).Error; err != nil {
+			return err
+		}
+
+		for _, url := range urls {
+			if expiresAt != nil && url.ActivatesAt != nil && !url.ActivatesAt.Before(*expiresAt) {
+				conflicts = append(conflicts, url.ID)
+			}
+		}
+		if len(conflicts) > 0 {
+			return ErrBulkConflict
+		}
+
+		if err := tx.Model(&models.URL{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"expires_at": expiresAt,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return createURLRevisions(tx, revisions)
+	})
+	return conflicts, err
+}
+
+// BulkAddURLTagsQueue adds the tags to all URLs, tags a URL already has are kept once
+func BulkAddURLTagsQueue(ids []uint64, tagIDs []uint64) error {
+	rows := make([]map[string]interface{}, 0, len(ids)*len(tagIDs))
+	for _, id := range ids {
+		for _, tagID := range tagIDs {
+			rows = append(rows, map[string]interface{}{"url_id": id, "tag_id": tagID})
+		}
+	}
+
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("url_tags").Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 1000).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.URL{}).Where("id IN ?", ids).Update("updated_at", time.Now()).Error
+	})
+}
+
+// BulkRemoveURLTagsQueue removes the tags from all URLs
+func BulkRemoveURLTagsQueue(ids []uint64, tagIDs []uint64) error {
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM url_tags WHERE url_id IN ? AND tag_id IN ?", ids, tagIDs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.URL{}).Where("id IN ?", ids).Update("updated_at", time.Now()).Error
+	})
+}
+
+// BulkDeleteURLsQueue moves all URLs to the trash
+func BulkDeleteURLsQueue(ids []uint64) error {
+	return db.GetDB().Where("id IN ?", ids).Delete(&models.URL{}).Error
+}
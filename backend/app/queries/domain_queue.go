@@ -52,9 +52,15 @@ func UpdateDomain(domain models.Domain) *gorm.DB {
 	return result
 }
 
-// DeleteDomain deletes a domain by ID
+// DeleteDomain deletes a domain by ID along with its fallback pages
 func DeleteDomain(id uint64) *gorm.DB {
-	result := db.GetDB().Delete(&models.Domain{}, id)
+	result := db.GetDB().Where("domain_id = ?", id).Delete(&models.FallbackPage{})
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to delete domain fallback pages: %v", result.Error)
+		return result
+	}
+
+	result = db.GetDB().Delete(&models.Domain{}, id)
 	if result.Error != nil {
 		logger.Log.Sugar().Errorf("Failed to delete domain: %v", result.Error)
 	}
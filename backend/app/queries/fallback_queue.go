@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// GetFallbackPagesQueue retrieves the fallback pages of a workspace, or of one of its domains
+func GetFallbackPagesQueue(workspaceID, domainID uint64) ([]models.FallbackPage, *gorm.DB) {
+	var pages []models.FallbackPage
+	result := db.GetDB().Where("workspace_id = ? AND domain_id = ?", workspaceID, domainID).Order("kind ASC").Find(&pages)
+	return pages, result
+}
+
+// GetFallbackPageQueue retrieves the fallback page of a kind, the ID is 0 when it is not set
+func GetFallbackPageQueue(workspaceID, domainID uint64, kind string) (models.FallbackPage, *gorm.DB) {
+	var page models.FallbackPage
+	result := db.GetDB().Where("workspace_id = ? AND domain_id = ? AND kind = ?", workspaceID, domainID, kind).Limit(1).Find(&page)
+	return page, result
+}
+
+// FindFallbackPage retrieves the fallback page shown for a domain, a page of the domain itself
+// takes precedence over the workspace wide one. The ID is 0 when neither is set.
+func FindFallbackPage(workspaceID, domainID uint64, kind string) (models.FallbackPage, *gorm.DB) {
+	var page models.FallbackPage
+	result := db.GetDB().
+		Where("workspace_id = ? AND domain_id IN ? AND kind = ?", workspaceID, []uint64{domainID, 0}, kind).
+		Order("domain_id DESC").
+		Limit(1).
+		Find(&page)
+	return page, result
+}
+
+// SaveFallbackPageQueue creates or updates a fallback page
+func SaveFallbackPageQueue(page models.FallbackPage) *gorm.DB {
+	page.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&page)
+	return result
+}
+
+// DeleteFallbackPageQueue deletes the fallback page of a kind
+func DeleteFallbackPageQueue(workspaceID, domainID uint64, kind string) *gorm.DB {
+	result := db.GetDB().Where("workspace_id = ? AND domain_id = ? AND kind = ?", workspaceID, domainID, kind).Delete(&models.FallbackPage{})
+	return result
+}
@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateFolderQueue creates a new folder in a workspace
+func CreateFolderQueue(folder models.Folder) *gorm.DB {
+	result := db.GetDB().Create(&folder)
+	return result
+}
+
+// GetFoldersByWorkspaceID retrieves all folders of a workspace
+func GetFoldersByWorkspaceID(workspaceID uint64) ([]models.Folder, *gorm.DB) {
+	var folders []models.Folder
+	result := db.GetDB().Where("workspace_id = ?", workspaceID).Order("name ASC").Find(&folders)
+	return folders, result
+}
+
+// GetFolderByID retrieves a folder of a workspace by its ID
+func GetFolderByID(workspaceID, folderID uint64) (models.Folder, *gorm.DB) {
+	var folder models.Folder
+	result := db.GetDB().Where("id = ? AND workspace_id = ?", folderID, workspaceID).First(&folder)
+	return folder, result
+}
+
+// UpdateFolderQueue updates an existing folder
+func UpdateFolderQueue(folder models.Folder) *gorm.DB {
+	folder.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&folder)
+	return result
+}
+
+// DeleteFolderQueue deletes a folder, its subfolders move up to its parent
+// and its URLs, including trashed ones, are left outside any folder
+func DeleteFolderQueue(folder models.Folder) error {
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Folder{}).Where("parent_id = ?", folder.ID).Update("parent_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.URL{}).Where("folder_id = ?", folder.ID).Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Folder{}, folder.ID).Error
+	})
+}
@@ -0,0 +1,56 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateGeoRuleQueue creates a new geo rule for a URL
+func CreateGeoRuleQueue(rule models.URLGeoRule) *gorm.DB {
+	result := db.GetDB().Create(&rule)
+	return result
+}
+
+// GetGeoRulesByURLID retrieves all geo rules of a URL
+func GetGeoRulesByURLID(urlID uint64) ([]models.URLGeoRule, *gorm.DB) {
+	var rules []models.URLGeoRule
+	result := db.GetDB().Where("url_id = ?", urlID).Order("country_code ASC").Find(&rules)
+	return rules, result
+}
+
+// GetGeoRuleByID retrieves a geo rule of a URL by its ID
+func GetGeoRuleByID(urlID, ruleID uint64) (models.URLGeoRule, *gorm.DB) {
+	var rule models.URLGeoRule
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).First(&rule)
+	return rule, result
+}
+
+// GetGeoRuleByCountry retrieves the geo rule of a URL for a country
+func GetGeoRuleByCountry(urlID uint64, countryCode string) (models.URLGeoRule, *gorm.DB) {
+	var rule models.URLGeoRule
+	result := db.GetDB().Where("url_id = ? AND country_code = ?", urlID, countryCode).Limit(1).Find(&rule)
+	return rule, result
+}
+
+// CheckGeoRuleExists checks if a URL already has a rule for a country
+func CheckGeoRuleExists(urlID uint64, countryCode string) (bool, error) {
+	var count int64
+	result := db.GetDB().Model(&models.URLGeoRule{}).Where("url_id = ? AND country_code = ?", urlID, countryCode).Count(&count)
+	return count > 0, result.Error
+}
+
+// UpdateGeoRuleQueue updates an existing geo rule
+func UpdateGeoRuleQueue(rule models.URLGeoRule) *gorm.DB {
+	rule.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&rule)
+	return result
+}
+
+// DeleteGeoRuleQueue deletes a geo rule of a URL
+func DeleteGeoRuleQueue(urlID, ruleID uint64) *gorm.DB {
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).Delete(&models.URLGeoRule{})
+	return result
+}
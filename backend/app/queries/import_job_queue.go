@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// importBatchSize is the number of URLs inserted per statement by an atomic import
+const importBatchSize = 500
+
+// CreateImportJobQueue creates a new import job
+func CreateImportJobQueue(job models.ImportJob) *gorm.DB {
+	result := db.GetDB().Create(&job)
+	return result
+}
+
+// GetImportJobByID retrieves an import job of a workspace by its ID
+func GetImportJobByID(workspaceID, jobID uint64) (models.ImportJob, *gorm.DB) {
+	var job models.ImportJob
+	result := db.GetDB().Where("id = ? AND workspace_id = ?", jobID, workspaceID).First(&job)
+	return job, result
+}
+
+// UpdateImportJobQueue updates the status and report of an import job
+func UpdateImportJobQueue(job models.ImportJob) *gorm.DB {
+	job.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&job)
+	return result
+}
+
+// CreateURLsInTransaction creates all URLs with their tags, or none of them if one fails
+func CreateURLsInTransaction(urls []models.URL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Tags.*").CreateInBatches(&urls, importBatchSize).Error
+	})
+}
+
+// CreateURLWithTagsQueue creates a single URL, linking it to existing tags
+func CreateURLWithTagsQueue(url models.URL) *gorm.DB {
+	result := db.GetDB().Omit("Tags.*").Create(&url)
+	return result
+}
+
+// FailUnfinishedImportJobsQueue marks every pending or running import job as failed
+func FailUnfinishedImportJobsQueue(errMsg string) *gorm.DB {
+	now := time.Now()
+	result := db.GetDB().Model(&models.ImportJob{}).
+		Where("status IN ?", []string{models.ImportStatusPending, models.ImportStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.ImportStatusFailed,
+			"error":        errMsg,
+			"completed_at": now,
+			"updated_at":   now,
+		})
+	return result
+}
@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateLanguageRuleQueue creates a new language rule for a URL
+func CreateLanguageRuleQueue(rule models.URLLanguageRule) *gorm.DB {
+	result := db.GetDB().Create(&rule)
+	return result
+}
+
+// GetLanguageRulesByURLID retrieves all language rules of a URL
+func GetLanguageRulesByURLID(urlID uint64) ([]models.URLLanguageRule, *gorm.DB) {
+	var rules []models.URLLanguageRule
+	result := db.GetDB().Where("url_id = ?", urlID).Order("language ASC").Find(&rules)
+	return rules, result
+}
+
+// GetLanguageRuleByID retrieves a language rule of a URL by its ID
+func GetLanguageRuleByID(urlID, ruleID uint64) (models.URLLanguageRule, *gorm.DB) {
+	var rule models.URLLanguageRule
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).First(&rule)
+	return rule, result
+}
+
+// CheckLanguageRuleExists checks if a URL already has a rule for a language
+func CheckLanguageRuleExists(urlID uint64, language string) (bool, error) {
+	var count int64
+	result := db.GetDB().Model(&models.URLLanguageRule{}).Where("url_id = ? AND language = ?", urlID, language).Count(&count)
+	return count > 0, result.Error
+}
+
+// UpdateLanguageRuleQueue updates an existing language rule
+func UpdateLanguageRuleQueue(rule models.URLLanguageRule) *gorm.DB {
+	rule.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&rule)
+	return result
+}
+
+// DeleteLanguageRuleQueue deletes a language rule of a URL
+func DeleteLanguageRuleQueue(urlID, ruleID uint64) *gorm.DB {
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).Delete(&models.URLLanguageRule{})
+	return result
+}
@@ -0,0 +1,42 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateScheduleRuleQueue creates a new schedule rule for a URL
+func CreateScheduleRuleQueue(rule models.URLScheduleRule) *gorm.DB {
+	result := db.GetDB().Create(&rule)
+	return result
+}
+
+// GetScheduleRulesByURLID retrieves all schedule rules of a URL in evaluation order
+func GetScheduleRulesByURLID(urlID uint64) ([]models.URLScheduleRule, *gorm.DB) {
+	var rules []models.URLScheduleRule
+	result := db.GetDB().Where("url_id = ?", urlID).Order("created_at ASC").Find(&rules)
+	return rules, result
+}
+
+// GetScheduleRuleByID retrieves a schedule rule of a URL by its ID
+func GetScheduleRuleByID(urlID, ruleID uint64) (models.URLScheduleRule, *gorm.DB) {
+	var rule models.URLScheduleRule
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).First(&rule)
+	return rule, result
+}
+
+// UpdateScheduleRuleQueue updates an existing schedule rule
+func UpdateScheduleRuleQueue(rule models.URLScheduleRule) *gorm.DB {
+	rule.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&rule)
+	return result
+}
+
+// DeleteScheduleRuleQueue deletes a schedule rule of a URL
+func DeleteScheduleRuleQueue(urlID, ruleID uint64) *gorm.DB {
+	result := db.GetDB().Where("id = ? AND url_id = ?", ruleID, urlID).Delete(&models.URLScheduleRule{})
+	return result
+}
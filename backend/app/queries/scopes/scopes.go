@@ -0,0 +1,16 @@
+// Package scopes holds the query conditions shared by several queries, kept apart from the queries
+// so they can be checked without a database.
+package scopes
+
+import "gorm.io/gorm"
+
+// DefaultDomainID is the domain ID of the URLs on the default short domain
+const DefaultDomainID uint64 = 0
+
+// ShortCodeOnDomain limits a URL query to a short code on one domain. Short codes are only unique per
+// domain, so a lookup without the domain could return the URL of another domain with the same code.
+func ShortCodeOnDomain(shortCode string, domainID uint64) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("short_code = ? AND domain_id = ?", shortCode, domainID)
+	}
+}
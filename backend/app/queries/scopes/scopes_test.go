@@ -0,0 +1,56 @@
+package scopes
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// url is the part of the URL model the queries need
+type url struct {
+	ID        uint64
+	ShortCode string
+	DomainID  uint64
+}
+
+func (url) TableName() string { return "urls" }
+
+// dryRun returns a session that builds the SQL of queries without a database
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open returned error: %v", err)
+	}
+	return db
+}
+
+func TestShortCodeOnDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		domainID uint64
+	}{
+		{"default domain", DefaultDomainID},
+		{"custom domain", 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found url
+			stmt := dryRun(t).Scopes(ShortCodeOnDomain("abc", tt.domainID)).First(&found).Statement
+
+			wantSQL := `SELECT * FROM "urls" WHERE short_code = $1 AND domain_id = $2 ORDER BY "urls"."id" LIMIT $3`
+			if got := stmt.SQL.String(); got != wantSQL {
+				t.Errorf("SQL = %q, want %q", got, wantSQL)
+			}
+			if want := []any{"abc", tt.domainID, 1}; !reflect.DeepEqual(stmt.Vars, want) {
+				t.Errorf("Vars = %v, want %v", stmt.Vars, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateTagQueue creates a new tag in a workspace
+func CreateTagQueue(tag models.Tag) *gorm.DB {
+	result := db.GetDB().Create(&tag)
+	return result
+}
+
+// GetTagsByWorkspaceID retrieves all tags of a workspace
+func GetTagsByWorkspaceID(workspaceID uint64) ([]models.Tag, *gorm.DB) {
+	var tags []models.Tag
+	result := db.GetDB().Where("workspace_id = ?", workspaceID).Order("name ASC").Find(&tags)
+	return tags, result
+}
+
+// GetTagByID retrieves a tag of a workspace by its ID
+func GetTagByID(workspaceID, tagID uint64) (models.Tag, *gorm.DB) {
+	var tag models.Tag
+	result := db.GetDB().Where("id = ? AND workspace_id = ?", tagID, workspaceID).First(&tag)
+	return tag, result
+}
+
+// GetTagsByIDs retrieves the tags of a workspace with the given IDs, unknown IDs are skipped
+func GetTagsByIDs(workspaceID uint64, tagIDs []uint64) ([]models.Tag, *gorm.DB) {
+	var tags []models.Tag
+	result := db.GetDB().Where("workspace_id = ? AND id IN ?", workspaceID, tagIDs).Order("name ASC").Find(&tags)
+	return tags, result
+}
+
+// GetTagsByURLID retrieves the tags of a URL
+func GetTagsByURLID(urlID uint64) ([]models.Tag, *gorm.DB) {
+	var tags []models.Tag
+	result := db.GetDB().
+		Joins("JOIN url_tags ON url_tags.tag_id = tags.id").
+		Where("url_tags.url_id = ?", urlID).
+		Order("tags.name ASC").
+		Find(&tags)
+	return tags, result
+}
+
+// CheckTagNameExists checks if a workspace already has a tag with the name
+func CheckTagNameExists(workspaceID uint64, name string) (bool, error) {
+	var count int64
+	result := db.GetDB().Model(&models.Tag{}).Where("workspace_id = ? AND name = ?", workspaceID, name).Count(&count)
+	return count > 0, result.Error
+}
+
+// UpdateTagQueue updates an existing tag
+func UpdateTagQueue(tag models.Tag) *gorm.DB {
+	tag.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&tag)
+	return result
+}
+
+// DeleteTagQueue deletes a tag of a workspace, the url_tags rows cascade so its URLs only lose the tag
+func DeleteTagQueue(workspaceID, tagID uint64) *gorm.DB {
+	result := db.GetDB().Where("id = ? AND workspace_id = ?", tagID, workspaceID).Delete(&models.Tag{})
+	return result
+}
+
+// ReplaceURLTagsQueue sets the tags of a URL
+func ReplaceURLTagsQueue(url models.URL, tags []models.Tag) error {
+	association := db.GetDB().Model(&url).Omit("Tags.*").Association("Tags")
+	if len(tags) == 0 {
+		return association.Clear()
+	}
+	return association.Replace(tags)
+}
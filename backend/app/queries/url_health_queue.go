@@ -0,0 +1,50 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// GetDueURLChecksQueue retrieves the active workspace URLs whose destination is due for a check, the ones never
+// checked first. Anonymous URLs have no one to report a broken destination to and are never checked.
+// A URL is due when it was never checked, its next check time has passed or its destination changed since.
+func GetDueURLChecksQueue(now time.Time, limit int) ([]models.URL, *gorm.DB) {
+	var urls []models.URL
+	result := db.GetDB().
+		Select("urls.id", "urls.original_url").
+		Joins("LEFT JOIN url_healths ON url_healths.url_id = urls.id").
+		Where("urls.workspace_id IS NOT NULL").
+		Where("urls.blocked_at IS NULL").
+		Where("(urls.activates_at IS NULL OR urls.activates_at <= ?)", now).
+		Where("(urls.expires_at IS NULL OR urls.expires_at > ?)", now).
+		Where("(urls.max_clicks IS NULL OR urls.total_clicks < urls.max_clicks)").
+		Where("(url_healths.url_id IS NULL OR url_healths.next_check_at <= ? OR url_healths.destination <> urls.original_url)", now).
+		Preload("Health").
+		Order("url_healths.next_check_at ASC NULLS FIRST, urls.id").
+		Limit(limit).
+		Find(&urls)
+	return urls, result
+}
+
+// SaveURLHealthQueue creates or replaces the health of a URL destination
+func SaveURLHealthQueue(health models.URLHealth) *gorm.DB {
+	result := db.GetDB().Save(&health)
+	return result
+}
+
+// GetBrokenURLsQueue retrieves the URLs of a workspace whose current destination is broken, most recently checked first
+func GetBrokenURLsQueue(workspaceID uint64) ([]models.URL, *gorm.DB) {
+	var urls []models.URL
+	result := db.GetDB().
+		Joins("JOIN url_healths ON url_healths.url_id = urls.id").
+		Where("urls.workspace_id = ? AND url_healths.status = ?", workspaceID, models.URLHealthBroken).
+		Where("url_healths.destination = urls.original_url").
+		Preload("Domain").
+		Preload("Health").
+		Order("url_healths.checked_at DESC, urls.id").
+		Find(&urls)
+	return urls, result
+}
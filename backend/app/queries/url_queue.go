@@ -1,21 +1,35 @@
 package queries
 
 import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries/scopes"
 	db "github.com/yorukot/zipt/pkg/database"
 	"gorm.io/gorm"
 )
 
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// IsShortCodeConflict reports whether the error is caused by a short code another URL of the domain already uses
+func IsShortCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == models.ShortCodeIndex
+}
+
 // CreateURLQueue creates a new URL record in the database
 func CreateURLQueue(url models.URL) *gorm.DB {
 	result := db.GetDB().Create(&url)
 	return result
 }
 
-// GetURLQueueByShortCode retrieves a URL by its short code
+// GetURLQueueByShortCode retrieves a URL of the default domain by its short code
 func GetURLQueueByShortCode(shortCode string) (models.URL, *gorm.DB) {
 	var url models.URL
-	result := db.GetDB().Where("short_code = ?", shortCode).First(&url)
+	result := db.GetDB().Scopes(scopes.ShortCodeOnDomain(shortCode, scopes.DefaultDomainID)).First(&url)
 	return url, result
 }
 
@@ -32,16 +46,16 @@ func DeleteURLQueue(shortCode string) *gorm.DB {
 	return result
 }
 
-// DeleteURLQueueByID deletes a URL by its ID
+// DeleteURLQueueByID moves a URL to the trash by its ID
 func DeleteURLQueueByID(id uint64) *gorm.DB {
 	result := db.GetDB().Where("id = ?", id).Delete(&models.URL{})
 	return result
 }
 
-// CheckShortCodeExists checks if a short code is already in use
+// CheckShortCodeExists checks if a short code is already in use, trashed URLs keep their short code
 func CheckShortCodeExists(shortCode string) (bool, error) {
 	var count int64
-	result := db.GetDB().Model(&models.URL{}).Where("short_code = ?", shortCode).Count(&count)
+	result := db.GetDB().Unscoped().Model(&models.URL{}).Where("short_code = ?", shortCode).Count(&count)
 	return count > 0, result.Error
 }
 
@@ -55,13 +69,58 @@ func GetURLsByUserID(userID uint64) ([]models.URL, error) {
 // GetURLByShortCodeAndDomain retrieves a URL by its short code and domain ID
 func GetURLByShortCodeAndDomain(shortCode string, domainID uint64) (models.URL, *gorm.DB) {
 	var url models.URL
-	result := db.GetDB().Where("short_code = ? AND domain_id = ?", shortCode, domainID).First(&url)
+	result := db.GetDB().Scopes(scopes.ShortCodeOnDomain(shortCode, domainID)).First(&url)
 	return url, result
 }
 
-// CheckShortCodeExistsByDomain checks if a short code is already in use for a specific domain
+// CheckShortCodeExistsByDomain checks if a short code is already in use for a specific domain,
+// trashed URLs keep their short code
 func CheckShortCodeExistsByDomain(shortCode string, domainID uint64) (bool, error) {
 	var count int64
-	result := db.GetDB().Model(&models.URL{}).Where("short_code = ? AND domain_id = ?", shortCode, domainID).Count(&count)
+	result := db.GetDB().Unscoped().Model(&models.URL{}).Where("short_code = ? AND domain_id = ?", shortCode, domainID).Count(&count)
 	return count > 0, result.Error
 }
+
+// ClaimURLClick atomically counts a click on a click limited URL.
+// It returns false when the URL has already reached its click limit.
+func ClaimURLClick(id uint64) (bool, error) {
+	result := db.GetDB().Model(&models.URL{}).
+		Where("id = ? AND (max_clicks IS NULL OR total_clicks < max_clicks)", id).
+		Update("total_clicks", gorm.Expr("total_clicks + ?", 1))
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetTrashedURLsQueue retrieves the trashed URLs of a workspace, most recently deleted first
+func GetTrashedURLsQueue(workspaceID uint64) ([]models.URL, *gorm.DB) {
+	var urls []models.URL
+	result := db.GetDB().Unscoped().Preload("Domain").
+		Where("workspace_id = ? AND deleted_at IS NOT NULL", workspaceID).
+		Order("deleted_at DESC").
+		Find(&urls)
+	return urls, result
+}
+
+// GetTrashedURLByID retrieves a trashed URL of a workspace by its ID
+func GetTrashedURLByID(workspaceID, id uint64) (models.URL, *gorm.DB) {
+	var url models.URL
+	result := db.GetDB().Unscoped().Where("id = ? AND workspace_id = ? AND deleted_at IS NOT NULL", id, workspaceID).First(&url)
+	return url, result
+}
+
+// RestoreURLQueue takes a URL out of the trash
+func RestoreURLQueue(id uint64) *gorm.DB {
+	result := db.GetDB().Unscoped().Model(&models.URL{}).Where("id = ?", id).Update("deleted_at", nil)
+	return result
+}
+
+// PurgeURLQueueByID permanently deletes a trashed URL by its ID
+func PurgeURLQueueByID(id uint64) *gorm.DB {
+	result := db.GetDB().Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&models.URL{})
+	return result
+}
+
+// PurgeTrashedURLsQueue permanently deletes the URLs trashed before the given time
+func PurgeTrashedURLsQueue(before time.Time) *gorm.DB {
+	result := db.GetDB().Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.URL{})
+	return result
+}
@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -32,25 +34,196 @@ func DeleteURLQueueById(id uint64) *gorm.DB {
 	return result
 }
 
-// GetURLsByWorkspaceQueue gets all URLs for a specific workspace
-func GetURLsByWorkspaceQueue(workspaceID uint64, page, limit int) (urls []models.URL, total int64, result *gorm.DB) {
-	// Calculate offset
-	offset := (page - 1) * limit
+// Sort orders of the workspace URL list
+const (
+	URLSortCreatedAt = "created_at"
+	URLSortUpdatedAt = "updated_at"
+	URLSortClicks    = "clicks"
+)
 
-	// Get total count
-	query := db.GetDB().Model(&models.URL{}).Where("workspace_id = ?", workspaceID)
-	result = query.Count(&total)
-	if result.Error != nil {
-		logger.Log.Sugar().Errorf("Failed to count URLs for workspace %d: %v", workspaceID, result.Error)
-		return nil, 0, result
+// Expiry states the workspace URL list can be filtered by
+const (
+	URLExpiryActive  = "active"  // Not expired yet, including URLs that never expire
+	URLExpiryExpired = "expired" // Past the expiration time or the click limit
+	URLExpiryNever   = "never"   // Neither an expiration time nor a click limit
+)
+
+// URLFilter narrows down the URLs listed for a workspace
+type URLFilter struct {
+	Search        string     // Matched against the short code and the destination
+	TagIDs        []uint64   // URLs must have all of these tags
+	FolderID      *uint64    // URLs directly inside this folder
+	Unfiled       bool       // URLs outside of any folder
+	DomainID      *uint64    // URLs on this domain, 0 is the default domain
+	CreatedAfter  *time.Time // Inclusive
+	CreatedBefore *time.Time // Exclusive
+	Expiry        string     // One of the URLExpiry states, empty for all
+	MinClicks     *int64
+	MaxClicks     *int64
+}
+
+// URLCursor points at the last URL of a page, the next page starts right after it
+type URLCursor struct {
+	Time   time.Time `json:"t,omitempty"` // created_at or updated_at of the URL, depending on the sort
+	Clicks int64     `json:"c,omitempty"`
+	ID     uint64    `json:"id,string"`
+}
+
+// URLPage selects one page of the workspace URL list
+type URLPage struct {
+	SortBy    string // One of the URLSort orders
+	Ascending bool
+	Limit     int
+	After     *URLCursor // Nil for the first page
+}
+
+// NewURLCursor builds the cursor pointing at the given URL for the sort order
+func NewURLCursor(url models.URL, sortBy string) URLCursor {
+	cursor := URLCursor{ID: url.ID}
+	switch sortBy {
+	case URLSortClicks:
+		cursor.Clicks = url.TotalClicks
+	case URLSortUpdatedAt:
+		cursor.Time = url.UpdatedAt
+	default:
+		cursor.Time = url.CreatedAt
 	}
+	return cursor
+}
 
-	// Get paginated results
-	result = query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&urls)
-	if result.Error != nil {
-		logger.Log.Sugar().Errorf("Failed to get URLs for workspace %d: %v", workspaceID, result.Error)
-		return nil, 0, result
+// GetURLsPageByWorkspaceQueue retrieves one page of the filtered URLs of a workspace, with the total number of
+// matching URLs. The ID breaks ties between equal sort values so the keyset pagination never skips a URL.
+func GetURLsPageByWorkspaceQueue(workspaceID uint64, filter URLFilter, page URLPage) (urls []models.URL, total int64, hasMore bool, err error) {
+	query := filterURLsQuery(db.GetDB().Model(&models.URL{}).Where("urls.workspace_id = ?", workspaceID), filter)
+
+	if err = query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		logger.Log.Sugar().Errorf("Failed to count URLs for workspace %d: %v", workspaceID, err)
+		return nil, 0, false, err
+	}
+
+	column := "urls.created_at"
+	switch page.SortBy {
+	case URLSortClicks:
+		column = "urls.total_clicks"
+	case URLSortUpdatedAt:
+		column = "urls.updated_at"
+	}
+
+	direction, comparison := "DESC", "<"
+	if page.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	if page.After != nil {
+		var value interface{} = page.After.Time
+		if page.SortBy == URLSortClicks {
+			value = page.After.Clicks
+		}
+		query = query.Where(fmt.Sprintf("(%s, urls.id) %s (?, ?)", column, comparison), value, page.After.ID)
+	}
+
+	// One extra URL tells whether another page follows
+	err = query.
+		Preload("Domain").
+		Preload("Tags").
+		Preload("Health").
+		Order(fmt.Sprintf("%s %s, urls.id %s", column, direction, direction)).
+		Limit(page.Limit + 1).
+		Find(&urls).Error
+	if err != nil {
+		logger.Log.Sugar().Errorf("Failed to get URLs for workspace %d: %v", workspaceID, err)
+		return nil, 0, false, err
 	}
 
-	return urls, total, result
+	if len(urls) > page.Limit {
+		urls, hasMore = urls[:page.Limit], true
+	}
+
+	// Preloading skips the default domain (ID 0), attach it by hand
+	var defaultDomain *models.Domain
+	for i := range urls {
+		if urls[i].Domain != nil || urls[i].DomainID != 0 {
+			continue
+		}
+		if defaultDomain == nil {
+			defaultDomain = &models.Domain{}
+			if err := db.GetDB().First(defaultDomain, 0).Error; err != nil {
+				break
+			}
+		}
+		urls[i].Domain = defaultDomain
+	}
+
+	return urls, total, hasMore, nil
+}
+
+// filterURLsQuery applies the list filters to a query on the urls table
+func filterURLsQuery(query *gorm.DB, filter URLFilter) *gorm.DB {
+	if filter.Search != "" {
+		pattern := "%" + escapeLikePattern(filter.Search) + "%"
+		query = query.Where("(urls.short_code ILIKE ? OR urls.original_url ILIKE ?)", pattern, pattern)
+	}
+
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("urls.id IN (?)", db.GetDB().
+			Table("url_tags").
+			Select("url_id").
+			Where("tag_id IN ?", filter.TagIDs).
+			Group("url_id").
+			Having("COUNT(DISTINCT tag_id) = ?", len(filter.TagIDs)))
+	}
+
+	if filter.FolderID != nil {
+		query = query.Where("urls.folder_id = ?", *filter.FolderID)
+	} else if filter.Unfiled {
+		query = query.Where("urls.folder_id IS NULL")
+	}
+
+	if filter.DomainID != nil {
+		query = query.Where("urls.domain_id = ?", *filter.DomainID)
+	}
+
+	if filter.CreatedAfter != nil {
+		query = query.Where("urls.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("urls.created_at < ?", *filter.CreatedBefore)
+	}
+
+	now := time.Now()
+	switch filter.Expiry {
+	case URLExpiryExpired:
+		query = query.Where("(urls.expires_at < ? OR urls.total_clicks >= urls.max_clicks)", now)
+	case URLExpiryActive:
+		query = query.Where("(urls.expires_at IS NULL OR urls.expires_at >= ?) AND (urls.max_clicks IS NULL OR urls.total_clicks < urls.max_clicks)", now)
+	case URLExpiryNever:
+		query = query.Where("urls.expires_at IS NULL AND urls.max_clicks IS NULL")
+	}
+
+	if filter.MinClicks != nil {
+		query = query.Where("urls.total_clicks >= ?", *filter.MinClicks)
+	}
+	if filter.MaxClicks != nil {
+		query = query.Where("urls.total_clicks <= ?", *filter.MaxClicks)
+	}
+
+	return query
+}
+
+// escapeLikePattern escapes the wildcards of a LIKE pattern so the search matches them literally
+func escapeLikePattern(search string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+}
+
+// GetURLsInBatchesQueue walks the filtered URLs of a workspace one batch at a time,
+// so exports never hold every URL in memory
+func GetURLsInBatchesQueue(workspaceID uint64, filter URLFilter, batchSize int, process func([]models.URL) error) error {
+	var urls []models.URL
+	result := filterURLsQuery(db.GetDB().Model(&models.URL{}).Where("urls.workspace_id = ?", workspaceID), filter).
+		Preload("Domain").
+		Preload("Tags").
+		FindInBatches(&urls, batchSize, func(tx *gorm.DB, batch int) error {
+			return process(urls)
+		})
+	return result.Error
 }
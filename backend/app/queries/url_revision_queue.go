@@ -0,0 +1,67 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// createURLRevisions stores new revisions in the transaction of the change they record
+func createURLRevisions(tx *gorm.DB, revisions []models.URLRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	return tx.Create(&revisions).Error
+}
+
+// SaveURLQueue writes the columns of an updated URL and the revisions of the update, or nothing if one fails
+func SaveURLQueue(url models.URL, columns []string, revisions []models.URLRevision) error {
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&url).Select(columns).Updates(&url).Error; err != nil {
+			return err
+		}
+		return createURLRevisions(tx, revisions)
+	})
+}
+
+// GetURLRevisionsByURLID retrieves all revisions of a URL, the newest first
+func GetURLRevisionsByURLID(urlID uint64) ([]models.URLRevision, *gorm.DB) {
+	var revisions []models.URLRevision
+	result := db.GetDB().Where("url_id = ?", urlID).Order("created_at DESC, id DESC").Find(&revisions)
+	return revisions, result
+}
+
+// GetURLRevisionByID retrieves a revision of a URL by its ID
+func GetURLRevisionByID(urlID, revisionID uint64) (models.URLRevision, *gorm.DB) {
+	var revision models.URLRevision
+	result := db.GetDB().Where("id = ? AND url_id = ?", revisionID, urlID).First(&revision)
+	return revision, result
+}
+
+// GetURLIDsWithRevisions returns which of the URLs already have at least one revision
+func GetURLIDsWithRevisions(urlIDs []uint64) (map[uint64]bool, error) {
+	var ids []uint64
+	result := db.GetDB().Model(&models.URLRevision{}).Where("url_id IN ?", urlIDs).Distinct().Pluck("url_id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	withRevisions := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		withRevisions[id] = true
+	}
+	return withRevisions, nil
+}
+
+// GetURLRevisionsInRange retrieves the changes of a URL made within the time range, oldest first
+func GetURLRevisionsInRange(urlID uint64, start, end time.Time) ([]models.URLRevision, *gorm.DB) {
+	var revisions []models.URLRevision
+	result := db.GetDB().
+		Where("url_id = ? AND created_at BETWEEN ? AND ?", urlID, start, end).
+		Where("source <> ?", models.RevisionSourceInitial).
+		Order("created_at ASC, id ASC").
+		Find(&revisions)
+	return revisions, result
+}
@@ -0,0 +1,58 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ScanURLDestinationsQueue walks the destinations of every URL in batches of the given size
+func ScanURLDestinationsQueue(batchSize int, scan func(urls []models.URL) error) *gorm.DB {
+	var urls []models.URL
+	result := db.GetDB().
+		Select("id", "original_url", "prelaunch_url", "ios_deep_link", "ios_fallback_url", "android_deep_link",
+			"android_fallback_url", "og_image_url", "blocked_at", "blocked_reason").
+		FindInBatches(&urls, batchSize, func(tx *gorm.DB, batch int) error {
+			return scan(urls)
+		})
+	return result
+}
+
+// RuleDestination is the destination of a geo, language or schedule rule or of a variant of a URL
+type RuleDestination struct {
+	URLID          uint64
+	DestinationURL string
+}
+
+// GetRuleDestinationsQueue retrieves the rule and variant destinations of the URLs
+func GetRuleDestinationsQueue(urlIDs []uint64) ([]RuleDestination, *gorm.DB) {
+	var destinations []RuleDestination
+	result := db.GetDB().Raw(`
+		SELECT url_id, destination_url FROM url_geo_rules WHERE url_id IN ?
+		UNION ALL SELECT url_id, destination_url FROM url_language_rules WHERE url_id IN ?
+		UNION ALL SELECT url_id, destination_url FROM url_schedule_rules WHERE url_id IN ?
+		UNION ALL SELECT url_id, destination_url FROM url_variants WHERE url_id IN ?`,
+		urlIDs, urlIDs, urlIDs, urlIDs,
+	).Scan(&destinations)
+	return destinations, result
+}
+
+// BlockURLQueue disables a URL because one of its destinations is unsafe
+func BlockURLQueue(id uint64, reason string) *gorm.DB {
+	result := db.GetDB().Model(&models.URL{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"blocked_at":     time.Now(),
+		"blocked_reason": reason,
+	})
+	return result
+}
+
+// UnblockURLQueue enables a blocked URL again
+func UnblockURLQueue(id uint64) *gorm.DB {
+	result := db.GetDB().Model(&models.URL{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"blocked_at":     nil,
+		"blocked_reason": "",
+	})
+	return result
+}
@@ -0,0 +1,42 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreateVariantQueue creates a new variant for a URL
+func CreateVariantQueue(variant models.URLVariant) *gorm.DB {
+	result := db.GetDB().Create(&variant)
+	return result
+}
+
+// GetVariantsByURLID retrieves all variants of a URL
+func GetVariantsByURLID(urlID uint64) ([]models.URLVariant, *gorm.DB) {
+	var variants []models.URLVariant
+	result := db.GetDB().Where("url_id = ?", urlID).Order("created_at ASC").Find(&variants)
+	return variants, result
+}
+
+// GetVariantByID retrieves a variant of a URL by its ID
+func GetVariantByID(urlID, variantID uint64) (models.URLVariant, *gorm.DB) {
+	var variant models.URLVariant
+	result := db.GetDB().Where("id = ? AND url_id = ?", variantID, urlID).First(&variant)
+	return variant, result
+}
+
+// UpdateVariantQueue updates an existing variant
+func UpdateVariantQueue(variant models.URLVariant) *gorm.DB {
+	variant.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&variant)
+	return result
+}
+
+// DeleteVariantQueue deletes a variant of a URL
+func DeleteVariantQueue(urlID, variantID uint64) *gorm.DB {
+	result := db.GetDB().Where("id = ? AND url_id = ?", variantID, urlID).Delete(&models.URLVariant{})
+	return result
+}
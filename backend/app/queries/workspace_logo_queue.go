@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	db "github.com/yorukot/zipt/pkg/database"
+	"gorm.io/gorm"
+)
+
+// GetWorkspaceLogoQueue retrieves the logo of a workspace, the workspace ID is 0 when it has none
+func GetWorkspaceLogoQueue(workspaceID uint64) (models.WorkspaceLogo, *gorm.DB) {
+	var logo models.WorkspaceLogo
+	result := db.GetDB().Where("workspace_id = ?", workspaceID).Limit(1).Find(&logo)
+	return logo, result
+}
+
+// SaveWorkspaceLogoQueue creates or replaces the logo of a workspace
+func SaveWorkspaceLogoQueue(logo models.WorkspaceLogo) *gorm.DB {
+	logo.UpdatedAt = time.Now()
+	result := db.GetDB().Save(&logo)
+	return result
+}
+
+// DeleteWorkspaceLogoQueue deletes the logo of a workspace
+func DeleteWorkspaceLogoQueue(workspaceID uint64) *gorm.DB {
+	result := db.GetDB().Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceLogo{})
+	return result
+}
@@ -28,5 +28,8 @@ func DomainRoute(r *gin.RouterGroup) {
 
 		// Verify a domain
 		domainRoutes.GET("/:domainID/verify", domain.VerifyDomain)
+
+		// Update the link defaults of a domain (only workspace owners)
+		domainRoutes.PUT("/:domainID/settings", domain.UpdateDomainSettings)
 	}
 }
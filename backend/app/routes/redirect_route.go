@@ -7,6 +7,25 @@ import (
 
 // RedirectRoute handles shortcode redirects at the root level
 func RedirectRoute(c *gin.Context) {
+	// Unknown API paths match the short URL routes too
+	if shortener.IsReservedShortCode(c.Param("shortCode")) {
+		NotFoundRoute(c)
+		return
+	}
+
 	// Delegate to the actual redirect handler
 	shortener.RedirectURL(c)
 }
+
+// PreviewRoute handles shortcode previews at the root level
+func PreviewRoute(c *gin.Context) {
+	shortener.PreviewURL(c)
+}
+
+// NotFoundRoute responds to requests no route handles
+func NotFoundRoute(c *gin.Context) {
+	c.JSON(404, gin.H{
+		"error":   "resource_not_found",
+		"message": "Resource not found",
+	})
+}
@@ -21,13 +21,80 @@ func ShortenerRoute(r *gin.RouterGroup) {
 	protected.Use(middleware.CheckWorkspaceRoleAndStore(models.RoleMember))
 
 	// Workspace-specific routes
-	protected.POST("", shortener.CreateShortURL)     // Create short URL within workspace
-	protected.GET("/list", shortener.GetUserURLs)    // Get all URLs created within workspace
-	protected.PUT("/:urlID", shortener.UpdateURL)    // Update an existing URL (authenticated users only)
-	protected.DELETE("/:urlID", shortener.DeleteURL) // Delete an existing URL (authenticated users only)
+	protected.POST("", shortener.CreateShortURL)        // Create short URL within workspace
+	protected.GET("/list", shortener.GetUserURLs)       // Get all URLs created within workspace
+	protected.GET("/export", shortener.ExportURLs)      // Stream all URLs of the workspace as CSV, JSON or NDJSON
+	protected.POST("/bulk", shortener.BulkUpdateURLs)   // Apply one operation to many URLs at once
+	protected.GET("/broken", shortener.GetBrokenURLs)   // Get the URLs whose destination is broken
+	protected.PUT("/:urlID", shortener.UpdateURL)       // Update an existing URL (authenticated users only)
+	protected.DELETE("/:urlID", shortener.DeleteURL)    // Move an existing URL to the trash (authenticated users only)
+	protected.GET("/:urlID/qr", shortener.GetURLQRCode) // Render the QR code of a URL as PNG or SVG
+
+	// Bulk creation from CSV or JSON uploads
+	imports := protected.Group("/imports")
+	imports.POST("", shortener.ImportURLs)         // Import URLs, large imports run in the background
+	imports.GET("/:jobID", shortener.GetImportJob) // Get the status and per row report of an import
+
+	// Trash of deleted URLs
+	trash := protected.Group("/trash")
+	trash.GET("", shortener.GetTrashedURLs)             // Get all trashed URLs of the workspace
+	trash.POST("/:urlID/restore", shortener.RestoreURL) // Restore a trashed URL
+	trash.DELETE("/:urlID", shortener.PurgeURL)         // Permanently delete a trashed URL
+
+	// Workspace tags
+	tags := protected.Group("/tags")
+	tags.GET("", shortener.GetTags)             // Get all tags of the workspace
+	tags.POST("", shortener.CreateTag)          // Create a tag
+	tags.PUT("/:tagID", shortener.UpdateTag)    // Update a tag
+	tags.DELETE("/:tagID", shortener.DeleteTag) // Delete a tag, its URLs are kept
+
+	// Workspace folders
+	folders := protected.Group("/folders")
+	folders.GET("", shortener.GetFolders)                // Get all folders of the workspace
+	folders.POST("", shortener.CreateFolder)             // Create a folder
+	folders.PUT("/:folderID", shortener.UpdateFolder)    // Rename or move a folder
+	folders.DELETE("/:folderID", shortener.DeleteFolder) // Delete a folder, its URLs are kept
 
 	// URL-specific routes
 	analytics := protected.Group("/:urlID/analytics")
 	analytics.GET("", shortener.GetURLAnalytics)                 // Get analytics overview
 	analytics.GET("/timeseries", shortener.GetURLTimeSeriesData) // Get time series metrics of a specific type
+
+	// Card shown when the link is shared in chat apps and social networks
+	socialImage := protected.Group("/:urlID/og-image")
+	socialImage.POST("", shortener.UploadSocialImage)   // Upload the social card image to object storage
+	socialImage.DELETE("", shortener.DeleteSocialImage) // Remove the social card image
+
+	// Change history of the slug, destination, domain and expiry
+	revisions := protected.Group("/:urlID/revisions")
+	revisions.GET("", shortener.GetURLRevisions)                   // Get all revisions of a URL
+	revisions.POST("/:revisionID/rollback", shortener.RollbackURL) // Restore a URL from one of its revisions
+
+	// Country based destinations
+	geoRules := protected.Group("/:urlID/geo-rules")
+	geoRules.GET("", shortener.GetGeoRules)              // Get all geo rules of a URL
+	geoRules.POST("", shortener.CreateGeoRule)           // Add a geo rule to a URL
+	geoRules.PUT("/:ruleID", shortener.UpdateGeoRule)    // Update a geo rule
+	geoRules.DELETE("/:ruleID", shortener.DeleteGeoRule) // Delete a geo rule
+
+	// Accept-Language based destinations
+	languageRules := protected.Group("/:urlID/language-rules")
+	languageRules.GET("", shortener.GetLanguageRules)              // Get all language rules of a URL
+	languageRules.POST("", shortener.CreateLanguageRule)           // Add a language rule to a URL
+	languageRules.PUT("/:ruleID", shortener.UpdateLanguageRule)    // Update a language rule
+	languageRules.DELETE("/:ruleID", shortener.DeleteLanguageRule) // Delete a language rule
+
+	// Time window based destinations
+	scheduleRules := protected.Group("/:urlID/schedule-rules")
+	scheduleRules.GET("", shortener.GetScheduleRules)              // Get all schedule rules of a URL
+	scheduleRules.POST("", shortener.CreateScheduleRule)           // Add a schedule rule to a URL
+	scheduleRules.PUT("/:ruleID", shortener.UpdateScheduleRule)    // Update a schedule rule
+	scheduleRules.DELETE("/:ruleID", shortener.DeleteScheduleRule) // Delete a schedule rule
+
+	// Weighted A/B destinations
+	variants := protected.Group("/:urlID/variants")
+	variants.GET("", shortener.GetVariants)                 // Get all variants of a URL
+	variants.POST("", shortener.CreateVariant)              // Add a variant to a URL
+	variants.PUT("/:variantID", shortener.UpdateVariant)    // Update a variant
+	variants.DELETE("/:variantID", shortener.DeleteVariant) // Delete a variant
 }
@@ -31,10 +31,22 @@ func WorkspaceRoute(r *gin.RouterGroup) {
 	memberRoutes.GET("/:workspaceID/invitations", workspace.GetWorkspaceInvitations)          // Get all invitations for a workspace
 	memberRoutes.DELETE("/:workspaceID/invitation/:invitationID", workspace.RemoveInvitation) // Remove an invitation
 	memberRoutes.GET("/:workspaceID/users", workspace.ListWorkspaceUsers)                     // Get all users in workspace
+	memberRoutes.GET("/:workspaceID/logo", workspace.GetWorkspaceLogo)                        // Get the logo embedded in QR codes
 
 	// Owner routes - require owner role
 	ownerRoutes.Use(middleware.CheckWorkspaceRoleAndStore(models.RoleOwner))
-	ownerRoutes.PUT("/:workspaceID", workspace.UpdateWorkspace)            // Update workspace
-	ownerRoutes.DELETE("/:workspaceID", workspace.DeleteWorkspace)         // Delete workspace
-	ownerRoutes.DELETE("/:workspaceID/user/:userId", workspace.RemoveUser) // Remove users
+	ownerRoutes.PUT("/:workspaceID", workspace.UpdateWorkspace)                  // Update workspace
+	ownerRoutes.PUT("/:workspaceID/settings", workspace.UpdateWorkspaceSettings) // Update workspace link defaults
+	ownerRoutes.DELETE("/:workspaceID", workspace.DeleteWorkspace)               // Delete workspace
+	ownerRoutes.DELETE("/:workspaceID/user/:userId", workspace.RemoveUser)       // Remove users
+	ownerRoutes.PUT("/:workspaceID/logo", workspace.UpdateWorkspaceLogo)         // Set the logo embedded in QR codes
+	ownerRoutes.DELETE("/:workspaceID/logo", workspace.DeleteWorkspaceLogo)      // Remove the logo
+
+	// Fallback pages for missing, expired and inactive links, workspace wide or for a single domain
+	ownerRoutes.GET("/:workspaceID/fallbacks", workspace.GetFallbackPages)                             // Get workspace fallback pages
+	ownerRoutes.PUT("/:workspaceID/fallbacks/:kind", workspace.UpdateFallbackPage)                     // Set a workspace fallback page
+	ownerRoutes.DELETE("/:workspaceID/fallbacks/:kind", workspace.DeleteFallbackPage)                  // Remove a workspace fallback page
+	ownerRoutes.GET("/:workspaceID/domain/:domainID/fallbacks", workspace.GetFallbackPages)            // Get domain fallback pages
+	ownerRoutes.PUT("/:workspaceID/domain/:domainID/fallbacks/:kind", workspace.UpdateFallbackPage)    // Set a domain fallback page
+	ownerRoutes.DELETE("/:workspaceID/domain/:domainID/fallbacks/:kind", workspace.DeleteFallbackPage) // Remove a domain fallback page
 }
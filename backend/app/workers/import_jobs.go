@@ -0,0 +1,20 @@
+package workers
+
+import (
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+)
+
+// FailInterruptedImportJobs marks the import jobs still pending or running when the server stopped as failed.
+// Background imports run in a goroutine of the process, so they would otherwise stay running forever.
+func FailInterruptedImportJobs() {
+	result := queries.FailUnfinishedImportJobsQueue("import was interrupted by a server restart")
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to mark interrupted import jobs as failed: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Log.Sugar().Warnf("Marked %d interrupted import jobs as failed", result.RowsAffected)
+	}
+}
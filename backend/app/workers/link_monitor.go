@@ -0,0 +1,116 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/linkcheck"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/safety"
+)
+
+// Link monitor settings
+const (
+	linkMonitorInterval    = 5 * time.Minute  // How often due destinations are looked for
+	linkMonitorBatchSize   = 200              // Destinations checked together
+	linkMonitorMaxBatches  = 25               // Batches checked in a single run, the rest waits for the next one
+	linkMonitorConcurrency = 16               // Checks in flight across all hosts
+	linkRecheckInterval    = 6 * time.Hour    // Delay between checks of a healthy destination
+	linkRetryBase          = 15 * time.Minute // First delay after a failed check, doubled with every failure
+	linkRetryLimit         = 24 * time.Hour
+	maxHealthErrorLength   = 255
+)
+
+// StartLinkMonitor periodically checks the destinations of the active URLs and records whether they still respond.
+// Requests can't reach private network addresses unless the safety settings allow them.
+// It runs in the background until the process exits.
+func StartLinkMonitor() {
+	checker := linkcheck.New(linkcheck.NewClient(safety.AddressFilter()))
+
+	go func() {
+		ticker := time.NewTicker(linkMonitorInterval)
+		defer ticker.Stop()
+
+		for {
+			checkDueDestinations(checker)
+			<-ticker.C
+		}
+	}()
+}
+
+// checkDueDestinations runs a single pass of the link monitor
+func checkDueDestinations(checker *linkcheck.Checker) {
+	var checked, broken int
+
+	for range linkMonitorMaxBatches {
+		urls, result := queries.GetDueURLChecksQueue(time.Now(), linkMonitorBatchSize)
+		if result.Error != nil {
+			logger.Log.Sugar().Errorf("Failed to get the destinations to check: %v", result.Error)
+			return
+		}
+		if len(urls) == 0 {
+			break
+		}
+
+		destinations := make([]string, len(urls))
+		for i, url := range urls {
+			destinations[i] = url.OriginalURL
+		}
+
+		results := checker.CheckAll(context.Background(), destinations, linkMonitorConcurrency)
+		for i, url := range urls {
+			health := newURLHealth(url, results[i])
+			if result := queries.SaveURLHealthQueue(health); result.Error != nil {
+				logger.Log.Sugar().Errorf("Failed to save the health of URL %d: %v", url.ID, result.Error)
+				return
+			}
+			checked++
+			if health.Status == models.URLHealthBroken {
+				broken++
+			}
+		}
+
+		if len(urls) < linkMonitorBatchSize {
+			break
+		}
+	}
+
+	if checked > 0 {
+		logger.Log.Sugar().Infof("Link monitor checked %d destinations, %d are broken", checked, broken)
+	}
+}
+
+// newURLHealth records the check of the destination of a URL and schedules the next one,
+// broken destinations back off so dead hosts aren't hammered
+func newURLHealth(url models.URL, result linkcheck.Result) models.URLHealth {
+	health := models.URLHealth{
+		URLID:       url.ID,
+		Destination: url.OriginalURL,
+		Status:      models.URLHealthHealthy,
+		StatusCode:  result.StatusCode,
+		LatencyMs:   result.Latency.Milliseconds(),
+		CheckedAt:   result.CheckedAt,
+		NextCheckAt: result.CheckedAt.Add(linkRecheckInterval),
+	}
+
+	if !result.Broken() {
+		return health
+	}
+
+	health.Status = models.URLHealthBroken
+	health.Failures = 1
+	if url.Health != nil && url.Health.Destination == url.OriginalURL {
+		health.Failures = url.Health.Failures + 1
+	}
+	health.NextCheckAt = result.CheckedAt.Add(linkcheck.Backoff(health.Failures, linkRetryBase, linkRetryLimit))
+
+	if result.Err != nil {
+		health.Error = result.Err.Error()
+		if len(health.Error) > maxHealthErrorLength {
+			health.Error = health.Error[:maxHealthErrorLength]
+		}
+	}
+	return health
+}
@@ -0,0 +1,111 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/models"
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/safety"
+)
+
+// Safety scanner settings
+const (
+	safetyReloadInterval = 10 * time.Minute // How often the list files are read again
+	safetyScanBatchSize  = 500
+)
+
+// StartSafetyScanner reloads the destination blocklists periodically and scans the existing URLs whenever
+// they changed, blocking the URLs with a listed destination and enabling the ones no longer listed.
+// It runs in the background until the process exits.
+func StartSafetyScanner() {
+	go func() {
+		ticker := time.NewTicker(safetyReloadInterval)
+		defer ticker.Stop()
+
+		// The lists may have changed while the server was down
+		scanURLDestinations()
+
+		for range ticker.C {
+			changed, err := safety.Reload()
+			if err != nil {
+				logger.Log.Sugar().Warnf("Failed to reload the destination blocklists: %v", err)
+				continue
+			}
+			if changed {
+				scanURLDestinations()
+			}
+		}
+	}()
+}
+
+// scanURLDestinations checks the destinations of every URL against the current lists.
+// Only the lists are checked, resolving the hosts of every URL on each change would be too slow.
+func scanURLDestinations() {
+	var blocked, unblocked int
+
+	result := queries.ScanURLDestinationsQueue(safetyScanBatchSize, func(urls []models.URL) error {
+		ruleDestinations, err := getRuleDestinations(urls)
+		if err != nil {
+			return err
+		}
+
+		for _, url := range urls {
+			violation := checkURLLists(append(url.Destinations(), ruleDestinations[url.ID]...))
+
+			switch {
+			case violation != nil && url.BlockedAt == nil:
+				if result := queries.BlockURLQueue(url.ID, violation.Reason); result.Error != nil {
+					return result.Error
+				}
+				blocked++
+			case violation == nil && url.BlockedAt != nil:
+				if result := queries.UnblockURLQueue(url.ID); result.Error != nil {
+					return result.Error
+				}
+				unblocked++
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to scan URL destinations: %v", result.Error)
+		return
+	}
+
+	if blocked > 0 || unblocked > 0 {
+		logger.Log.Sugar().Infof("Destination scan blocked %d URLs and enabled %d URLs", blocked, unblocked)
+	}
+}
+
+// getRuleDestinations returns the rule and variant destinations of the URLs by URL ID
+func getRuleDestinations(urls []models.URL) (map[uint64][]string, error) {
+	ids := make([]uint64, len(urls))
+	for i, url := range urls {
+		ids[i] = url.ID
+	}
+
+	rows, result := queries.GetRuleDestinationsQueue(ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	destinations := make(map[uint64][]string)
+	for _, row := range rows {
+		destinations[row.URLID] = append(destinations[row.URLID], row.DestinationURL)
+	}
+	return destinations, nil
+}
+
+// checkURLLists returns the violation of the first listed destination
+func checkURLLists(destinations []string) *safety.Violation {
+	for _, destination := range destinations {
+		if destination == "" {
+			continue
+		}
+		if violation := safety.CheckLists(destination); violation != nil {
+			return violation
+		}
+	}
+	return nil
+}
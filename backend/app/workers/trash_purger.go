@@ -0,0 +1,41 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/yorukot/zipt/app/queries"
+	"github.com/yorukot/zipt/pkg/logger"
+	"github.com/yorukot/zipt/pkg/utils"
+)
+
+// trashPurgeInterval is how often trashed URLs past their retention are purged
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurger permanently deletes trashed URLs once they are past the retention period,
+// releasing their slugs. It runs in the background until the process exits.
+func StartTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgeTrashedURLs()
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeTrashedURLs runs a single purge of the trash
+func purgeTrashedURLs() {
+	retention := time.Duration(utils.TrashRetentionDays) * 24 * time.Hour
+
+	result := queries.PurgeTrashedURLsQueue(time.Now().Add(-retention))
+	if result.Error != nil {
+		logger.Log.Sugar().Errorf("Failed to purge trashed URLs: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Log.Sugar().Infof("Purged %d trashed URLs", result.RowsAffected)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/yorukot/zipt/app/routes"
+	"github.com/yorukot/zipt/app/workers"
 
 	// _ "github.com/yorukot/zipt/pkg/cache" uncomment this to use cache
 	// _ "github.com/yorukot/zipt/pkg/oauth" uncomment this to use oauth
@@ -15,6 +16,7 @@ import (
 	"github.com/yorukot/zipt/pkg/geoip"
 	"github.com/yorukot/zipt/pkg/logger"
 	"github.com/yorukot/zipt/pkg/middleware"
+	"github.com/yorukot/zipt/pkg/safety"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -23,6 +25,17 @@ func main() {
 	// Initialize GeoIP database
 	geoip.Init()
 
+	// Load the destination blocklists
+	safety.Init()
+
+	// Imports that were running in the background died with the previous process
+	workers.FailInterruptedImportJobs()
+
+	// Start background workers
+	workers.StartTrashPurger()
+	workers.StartSafetyScanner()
+	workers.StartLinkMonitor()
+
 	// Setup gin engine
 	gin.SetMode(gin.ReleaseMode)
 	if os.Getenv("GIN_MODE") == "debug" {
@@ -51,6 +64,14 @@ func main() {
 
 	// Configure redirect routes at the root level
 	root.GET("/:shortCode", routes.RedirectRoute)
+	root.POST("/:shortCode", routes.RedirectRoute) // Unlock form of password protected URLs
+
+	// Preview page, also served on /{code}+
+	root.GET("/preview/:shortCode", routes.PreviewRoute)
+
+	// Path forwarding, e.g. /{code}/docs/getting-started
+	root.GET("/:shortCode/*path", routes.RedirectRoute)
+	root.POST("/:shortCode/*path", routes.RedirectRoute)
 
 	r := root.Group("/api/v" + os.Getenv("VERSION"))
 
@@ -64,12 +85,7 @@ func main() {
 
 	printAppInfo()
 
-	root.NoRoute(func(c *gin.Context) {
-		c.JSON(404, gin.H{
-			"error":   "resource_not_found",
-			"message": "Resource not found",
-		})
-	})
+	root.NoRoute(routes.NotFoundRoute)
 
 	if err := root.Run(); err != nil {
 		logger.Log.Sugar().Fatal("Server failed to start: %v", err)
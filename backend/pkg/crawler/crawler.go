@@ -0,0 +1,65 @@
+// Package crawler recognises the bots of chat apps and social networks unfurling shared links,
+// and decides how a request on a short URL is served to them.
+package crawler
+
+import "strings"
+
+// socialCrawlers are lowercase user agent fragments of the bots that unfurl shared links
+var socialCrawlers = []string{
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"linkedinbot",
+	"slackbot",
+	"slack-imgproxy",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview",
+	"microsoft teams",
+	"pinterestbot",
+	"redditbot",
+	"mastodon",
+	"bluesky",
+	"embedly",
+	"iframely",
+	"vkshare",
+	"viber",
+	"line-poker",
+	"kakaotalk-scrap",
+	"google-pagerenderer",
+}
+
+// IsSocial reports whether the user agent belongs to a chat app or social network fetching a link preview
+func IsSocial(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return false
+	}
+
+	for _, crawler := range socialCrawlers {
+		if strings.Contains(userAgent, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decision is how a request on a short URL is served
+type Decision struct {
+	SocialCard bool // Serve the social card instead of redirecting
+	ClaimClick bool // Claim a click of the click limit before redirecting
+	Track      bool // Record the click in analytics
+}
+
+// Decide decides how a request is served. A crawler only gets the social card, and only when the link has one.
+// The user agent is set by the client, so every request that is redirected claims a click of the click limit,
+// whatever it claims to be. Redirected crawlers are left out of analytics: a forged user agent only hides a
+// visit from the statistics, it never gets past the limit.
+func Decide(userAgent string, hasSocialCard, clickLimited bool) Decision {
+	social := IsSocial(userAgent)
+	if hasSocialCard && social {
+		return Decision{SocialCard: true}
+	}
+	return Decision{ClaimClick: clickLimited, Track: !social}
+}
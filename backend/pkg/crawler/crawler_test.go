@@ -0,0 +1,80 @@
+package crawler
+
+import "testing"
+
+func TestIsSocial(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      bool
+	}{
+		{"Twitterbot/1.0", true},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSocial(tt.userAgent); got != tt.want {
+			t.Errorf("IsSocial(%q) = %t, want %t", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	const bot = "Twitterbot/1.0"
+	const browser = "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+
+	tests := []struct {
+		name          string
+		userAgent     string
+		hasSocialCard bool
+		clickLimited  bool
+		want          Decision
+	}{
+		{"crawler with card", bot, true, true, Decision{SocialCard: true}},
+		{"crawler without card", bot, false, false, Decision{}},
+		{"crawler without card on a click limited link", bot, false, true, Decision{ClaimClick: true}},
+		{"browser with card", browser, true, true, Decision{ClaimClick: true, Track: true}},
+		{"browser", browser, false, false, Decision{Track: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decide(tt.userAgent, tt.hasSocialCard, tt.clickLimited); got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A link with max_clicks=1 opened with a crawler user agent is only followed once,
+// a forged user agent never gets past the click limit
+func TestDecideCrawlerCannotBypassClickLimit(t *testing.T) {
+	maxClicks, totalClicks := int64(1), int64(0)
+	claim := func() bool {
+		if totalClicks >= maxClicks {
+			return false
+		}
+		totalClicks++
+		return true
+	}
+
+	redirects := 0
+	for range 5 {
+		decision := Decide("facebookexternalhit/1.1", false, true)
+		if decision.SocialCard {
+			t.Fatal("served a card for a link without one")
+		}
+		if !decision.ClaimClick {
+			t.Fatalf("crawler redirect not claimed: %+v", decision)
+		}
+		if claim() {
+			redirects++
+		}
+	}
+
+	if redirects != 1 {
+		t.Fatalf("got %d redirects, want 1 for max_clicks=1", redirects)
+	}
+}
@@ -0,0 +1,24 @@
+// Package csvcell protects CSV cells against formula injection. Spreadsheet apps run a cell starting with
+// =, +, - or @ as a formula, so exported values are prefixed with a quote which they display as text.
+package csvcell
+
+import "strings"
+
+// formulaPrefixes are the first characters that make spreadsheet apps read a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// Escape prefixes the value with a quote when spreadsheet apps would run it as a formula
+func Escape(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// Unescape removes the quote Escape added, so exported files can be imported again unchanged
+func Unescape(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package csvcell
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"https://example.com", "https://example.com"},
+		{"Spring sale", "Spring sale"},
+		{"=HYPERLINK(\"https://evil.example\")", "'=HYPERLINK(\"https://evil.example\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{"'quoted", "'quoted"},
+	}
+
+	for _, tt := range tests {
+		if got := Escape(tt.value); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUnescapeRoundTrip(t *testing.T) {
+	for _, value := range []string{"", "plain", "=1+1", "-50% off", "@mention", "'quoted", "'", "\tindented"} {
+		if got := Unescape(Escape(value)); got != value {
+			t.Errorf("Unescape(Escape(%q)) = %q", value, got)
+		}
+	}
+}
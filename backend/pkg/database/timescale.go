@@ -10,6 +10,14 @@ import (
 	"github.com/yorukot/zipt/pkg/logger"
 )
 
+// aggregatesVersion is the version of the continuous aggregate definitions in timescale.sql. Bump it whenever
+// the views change, installs with an older version drop them on the next start and they are created again.
+// The raw clicks are kept, so the new views are filled with the whole history.
+const aggregatesVersion = 2
+
+// aggregateViews are the continuous aggregates created by timescale.sql
+var aggregateViews = []string{"url_analytics_2min", "url_analytics_hourlies", "url_analytics_dailies", "url_analytics_monthlies"}
+
 // InitializeTimescale sets up TimescaleDB hypertables and continuous aggregation policies
 // This should be called after all models are auto-migrated
 func InitializeTimescale() error {
@@ -33,6 +41,12 @@ func InitializeTimescale() error {
 		return fmt.Errorf("TimescaleDB extension not enabled")
 	}
 
+	// Views of an older version would otherwise be kept as they are
+	if err := dropOutdatedAggregates(); err != nil {
+		logger.Log.Sugar().Errorf("Failed to migrate the TimescaleDB continuous aggregates: %v", err)
+		return err
+	}
+
 	// Split SQL file into individual statements and execute each one
 	statements := strings.Split(string(query), ";")
 	for i, stmt := range statements {
@@ -60,10 +74,42 @@ func InitializeTimescale() error {
 		}
 	}
 
+	if err := DB.Exec("UPDATE timescale_aggregates_version SET version = ?", aggregatesVersion).Error; err != nil {
+		logger.Log.Sugar().Errorf("Failed to record the TimescaleDB aggregates version: %v", err)
+		return err
+	}
+
 	logger.Log.Sugar().Info("TimescaleDB successfully configured with multi-level continuous aggregates")
 	return nil
 }
 
+// dropOutdatedAggregates drops the continuous aggregates when they were created by an older version of timescale.sql.
+// Their refresh and retention policies are dropped with them.
+func dropOutdatedAggregates() error {
+	if err := DB.Exec("CREATE TABLE IF NOT EXISTS timescale_aggregates_version (version integer NOT NULL)").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("INSERT INTO timescale_aggregates_version (version) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM timescale_aggregates_version)").Error; err != nil {
+		return err
+	}
+
+	var version int
+	if err := DB.Raw("SELECT version FROM timescale_aggregates_version").Scan(&version).Error; err != nil {
+		return err
+	}
+	if version >= aggregatesVersion {
+		return nil
+	}
+
+	logger.Log.Sugar().Infof("Recreating the TimescaleDB continuous aggregates, version %d to %d", version, aggregatesVersion)
+	for _, view := range aggregateViews {
+		if err := DB.Exec(fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s CASCADE", view)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // IsTimescaleDBEnabled checks if TimescaleDB is enabled by querying for the extension
 func IsTimescaleDBEnabled() bool {
 	var count int64
@@ -1,3 +1,5 @@
+-- Bump aggregatesVersion in timescale.go whenever the continuous aggregates change, so existing installs recreate them
+
 -- Convert url_analytics table to TimescaleDB hypertable
 SELECT create_hypertable(
         'url_analytics',
@@ -14,6 +16,10 @@ SELECT url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     time_bucket(INTERVAL '2 minutes', created_at) AS bucket_2min,
     COUNT(*) AS total_clicks
 FROM url_analytics
@@ -24,6 +30,10 @@ GROUP BY url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     bucket_2min;
 
 -- Create continuous aggregate view for hourly click counts
@@ -35,6 +45,10 @@ SELECT url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     time_bucket(INTERVAL '1 hour', created_at) AS bucket_hour,
     COUNT(*) AS total_clicks
 FROM url_analytics
@@ -45,6 +59,10 @@ GROUP BY url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     bucket_hour;
 
 -- Create continuous aggregate view for daily click counts
@@ -56,6 +74,10 @@ SELECT url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     time_bucket(INTERVAL '1 day', created_at) AS bucket_day,
     COUNT(*) AS total_clicks
 FROM url_analytics
@@ -66,6 +88,10 @@ GROUP BY url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     bucket_day;
 
 -- Create continuous aggregate view for monthly click counts
@@ -77,6 +103,10 @@ SELECT url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     time_bucket(INTERVAL '1 month', created_at) AS bucket_month,
     COUNT(*) AS total_clicks
 FROM url_analytics
@@ -87,6 +117,10 @@ GROUP BY url_id,
     device,
     browser,
     os,
+    geo_rule,
+    variant,
+    language,
+    source,
     bucket_month;
 
 -- Add policy for 2-minute aggregates
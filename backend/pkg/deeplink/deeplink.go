@@ -0,0 +1,52 @@
+// Package deeplink validates the deep links of short URLs. A deep link is either a web link or a URI with a
+// custom app scheme, like myapp://product/1. Schemes a browser runs or reads locally instead of handing them
+// to an app are refused, the app page of the short domain navigates to the deep link.
+package deeplink
+
+import (
+	"errors"
+	neturl "net/url"
+	"strings"
+)
+
+// Errors returned by Validate
+var (
+	ErrInvalid = errors.New("deep link must be a URI with a scheme")
+	ErrScheme  = errors.New("deep link scheme is not allowed")
+)
+
+// blockedSchemes run code or read data in the browser instead of opening an app
+var blockedSchemes = map[string]bool{
+	"javascript": true,
+	"data":       true,
+	"vbscript":   true,
+	"file":       true,
+	"blob":       true,
+	"about":      true,
+	"filesystem": true,
+}
+
+// Validate checks that the value is a web link or a URI with a custom app scheme
+func Validate(value string) error {
+	parsed, err := neturl.Parse(value)
+	if err != nil || parsed.Scheme == "" || (parsed.Host == "" && parsed.Opaque == "" && parsed.Path == "") {
+		return ErrInvalid
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme == "http" || scheme == "https" {
+		if parsed.Host == "" {
+			return ErrInvalid
+		}
+		return nil
+	}
+	if blockedSchemes[scheme] {
+		return ErrScheme
+	}
+	return nil
+}
+
+// Valid reports whether Validate accepts the value
+func Valid(value string) bool {
+	return Validate(value) == nil
+}
@@ -0,0 +1,42 @@
+package deeplink
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  error
+	}{
+		{"myapp://product/1", nil},
+		{"fb://profile/33138223345", nil},
+		{"intent://scan/#Intent;scheme=zxing;package=com.google.zxing.client.android;end", nil},
+		{"com.example.app:/callback", nil},
+		{"https://example.com/product/1", nil},
+		{"HTTP://example.com", nil},
+
+		{"javascript:alert(document.cookie)", ErrScheme},
+		{"JavaScript:alert(1)", ErrScheme},
+		{"javascript:void(0)//myapp://x", ErrScheme},
+		{"data:text/html,<script>alert(1)</script>", ErrScheme},
+		{"vbscript:msgbox(1)", ErrScheme},
+		{"file:///etc/passwd", ErrScheme},
+		{"blob:https://example.com/uuid", ErrScheme},
+		{"about:blank", ErrScheme},
+
+		{"", ErrInvalid},
+		{"product/1", ErrInvalid},
+		{"https:///path", ErrInvalid},
+		{" javascript:alert(1)", ErrInvalid},
+		{"java\tscript:alert(1)", ErrInvalid},
+		{"myapp:", ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.value); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tt.value, err, tt.want)
+		}
+	}
+}
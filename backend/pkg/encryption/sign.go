@@ -0,0 +1,63 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("the signed value is invalid")
+	ErrSignatureExpired = errors.New("the signed value has expired")
+)
+
+// SignValue signs a value with the JWT secret key, the result can be verified with VerifySignedValue
+func SignValue(value string, expiresAt time.Time) string {
+	payload := value + "|" + strconv.FormatInt(expiresAt.Unix(), 10)
+	signature := base64.RawURLEncoding.EncodeToString(sign(payload))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + signature
+}
+
+// VerifySignedValue checks the signature and expiration of a signed value and returns the original value
+func VerifySignedValue(signed string) (string, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(signed, ".")
+	if !found {
+		return "", ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, sign(string(payload))) {
+		return "", ErrInvalidSignature
+	}
+
+	separator := strings.LastIndex(string(payload), "|")
+	if separator < 0 {
+		return "", ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(string(payload[separator+1:]), 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	if time.Now().Unix() >= expiresAt {
+		return "", ErrSignatureExpired
+	}
+
+	return string(payload[:separator]), nil
+}
+
+// sign returns the HMAC-SHA256 of the payload
+func sign(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(JwtSecretKey))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
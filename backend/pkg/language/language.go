@@ -0,0 +1,122 @@
+// Package language negotiates the language of a request from its Accept-Language header. Language ranges
+// are matched against the available tags like the basic filtering of RFC 4647, with a fallback to related
+// tags, and ranges with a quality of zero exclude the languages they match.
+package language
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Range is a language range from an Accept-Language header with its quality value
+type Range struct {
+	Tag     string
+	Quality float64
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into lowercase language ranges, ordered by descending
+// quality. Ranges with a quality of zero are kept last, they exclude languages.
+func ParseAcceptLanguage(header string) []Range {
+	var ranges []Range
+
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			quality = parsed
+		}
+
+		ranges = append(ranges, Range{Tag: tag, Quality: quality})
+	}
+
+	// Keep the header order for ranges with the same quality
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Quality > ranges[j].Quality
+	})
+
+	return ranges
+}
+
+// Negotiate returns the best of the available lowercase language tags for an Accept-Language header.
+// Each range is matched exactly, then by dropping subtags (en-us matches en), then as a prefix
+// (en matches en-gb). A tag is never returned when the most specific range matching it has a quality
+// of zero, so en;q=0 excludes en-us too. An empty string is returned when nothing matches or the wildcard wins.
+func Negotiate(header string, available []string) string {
+	ranges := ParseAcceptLanguage(header)
+
+	var candidates []string
+	for _, tag := range available {
+		if !excluded(ranges, tag) {
+			candidates = append(candidates, tag)
+		}
+	}
+
+	for _, languageRange := range ranges {
+		if languageRange.Quality == 0 {
+			break
+		}
+		if languageRange.Tag == "*" {
+			return ""
+		}
+
+		for tag := languageRange.Tag; tag != ""; {
+			for _, candidate := range candidates {
+				if candidate == tag {
+					return candidate
+				}
+			}
+
+			cut := strings.LastIndex(tag, "-")
+			if cut < 0 {
+				break
+			}
+			tag = tag[:cut]
+		}
+
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, languageRange.Tag+"-") {
+				return candidate
+			}
+		}
+	}
+
+	return ""
+}
+
+// excluded reports whether the most specific range matching the tag has a quality of zero
+func excluded(ranges []Range, tag string) bool {
+	specificity, quality := -1, 1.0
+	for _, languageRange := range ranges {
+		length := len(languageRange.Tag)
+		if languageRange.Tag == "*" {
+			length = 0
+		} else if languageRange.Tag != tag && !strings.HasPrefix(tag, languageRange.Tag+"-") {
+			continue
+		}
+
+		// Ranges are ordered by quality, the first of equally specific ones wins
+		if length > specificity {
+			specificity, quality = length, languageRange.Quality
+		}
+	}
+	return specificity >= 0 && quality == 0
+}
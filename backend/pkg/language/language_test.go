@@ -0,0 +1,64 @@
+package language
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []Range
+	}{
+		{"", nil},
+		{"en-US", []Range{{"en-us", 1}}},
+		{"fr;q=0.5, en-GB , de;q=0.8", []Range{{"en-gb", 1}, {"de", 0.8}, {"fr", 0.5}}},
+		{"en;q=0.5, fr;q=0.5", []Range{{"en", 0.5}, {"fr", 0.5}}},
+		{"en;q=0, fr", []Range{{"fr", 1}, {"en", 0}}},
+		{"en;q=2, fr;q=abc, de;level=1", []Range{{"de", 1}, {"en", 0}, {"fr", 0}}},
+		{" , ;q=1, *;q=0.1", []Range{{"*", 0.1}}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"en", "en-us", "fr-ca", "zh-tw", "de"}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty header", "", ""},
+		{"exact", "de", "de"},
+		{"case insensitive", "EN-US", "en-us"},
+		{"quality order", "fr-ca;q=0.4, de;q=0.9", "de"},
+		{"header order breaks ties", "de, en", "de"},
+		{"drops subtags", "de-AT", "de"},
+		{"drops several subtags", "de-Latn-AT", "de"},
+		{"prefix", "fr", "fr-ca"},
+		{"no match", "ja, ko", ""},
+		{"wildcard wins", "*, de;q=0.5", ""},
+		{"match before the wildcard", "de, *;q=0.5", "de"},
+		{"excluded exact", "de;q=0, en", "en"},
+		{"region with a lower quality than the excluded language", "en;q=0, en-us;q=0.9", "en-us"},
+		{"exclusion blocks the prefix fallback", "fr-ca;q=0, fr", ""},
+		{"exclusion blocks dropping subtags", "en-gb, en;q=0", ""},
+		{"first of duplicate ranges wins", "en, en;q=0", "en"},
+		{"more specific range overrides the exclusion", "en;q=0, en-us", "en-us"},
+		{"wildcard exclusion", "*;q=0, de", "de"},
+		{"wildcard exclusion without match", "*;q=0, ja", ""},
+		{"only exclusions", "en;q=0, de;q=0", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header, available); got != tt.want {
+			t.Errorf("%s: Negotiate(%q) = %q, want %q", tt.name, tt.header, got, tt.want)
+		}
+	}
+}
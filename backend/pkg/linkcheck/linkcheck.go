@@ -0,0 +1,278 @@
+// Package linkcheck checks whether destination URLs still respond. A destination is requested with HEAD,
+// falling back to GET for servers that don't handle HEAD, with a timeout per request, a limit of concurrent
+// requests per host and retries with exponential backoff for transient failures.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Default checker settings
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultPerHost    = 2
+	DefaultRetries    = 2
+	DefaultRetryDelay = time.Second
+	DefaultUserAgent  = "ZiptLinkChecker/1.0"
+)
+
+// maxDrainSize is how much of a GET response body is read before closing it, so the connection can be reused
+const maxDrainSize = 64 << 10
+
+// ErrAddressNotAllowed is returned when a destination connects to an address the client refuses
+var ErrAddressNotAllowed = errors.New("destination address is not allowed")
+
+// Doer sends HTTP requests, *http.Client is one. Tests inject a client of a local server.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Result is the outcome of checking a destination
+type Result struct {
+	StatusCode int           // The final status code, 0 when no response was received
+	Latency    time.Duration // Time to the response of the final request
+	Err        error         // Why no response was received
+	CheckedAt  time.Time
+}
+
+// Broken reports whether the destination is dead: it didn't respond, is gone or fails on the server side.
+// Other client errors like 401, 403 and 429 mean the destination exists but refuses automated requests.
+func (r Result) Broken() bool {
+	return r.Err != nil || r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone ||
+		r.StatusCode >= http.StatusInternalServerError
+}
+
+// Checker checks destinations, it is safe for concurrent use
+type Checker struct {
+	Client     Doer
+	Timeout    time.Duration // Per request
+	PerHost    int           // Concurrent requests to a single host
+	Retries    int           // Extra attempts after a transient failure
+	RetryDelay time.Duration // Delay before the first retry, doubled after each one
+	UserAgent  string
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlots // Hosts with a request in flight or waiting
+}
+
+// hostSlots limits the concurrent requests to a host, it is removed once no request uses or waits for it
+type hostSlots struct {
+	slots chan struct{}
+	users int
+}
+
+// New creates a checker sending its requests with the client, with the default settings
+func New(client Doer) *Checker {
+	return &Checker{
+		Client:     client,
+		Timeout:    DefaultTimeout,
+		PerHost:    DefaultPerHost,
+		Retries:    DefaultRetries,
+		RetryDelay: DefaultRetryDelay,
+		UserAgent:  DefaultUserAgent,
+	}
+}
+
+// Check checks a single destination
+func (checker *Checker) Check(ctx context.Context, destination string) Result {
+	parsed, err := neturl.Parse(destination)
+	if err != nil || parsed.Hostname() == "" {
+		return Result{Err: errors.New("invalid destination URL"), CheckedAt: time.Now()}
+	}
+
+	release, err := checker.acquireHost(ctx, strings.ToLower(parsed.Hostname()))
+	if err != nil {
+		return Result{Err: err, CheckedAt: time.Now()}
+	}
+	defer release()
+
+	delay := checker.RetryDelay
+	for attempt := 0; ; attempt++ {
+		result := checker.probe(ctx, destination)
+		if attempt >= checker.Retries || !isTransient(result) || ctx.Err() != nil {
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// CheckAll checks the destinations with at most concurrency requests in flight, the results are in the same order
+func (checker *Checker) CheckAll(ctx context.Context, destinations []string, concurrency int) []Result {
+	results := make([]Result, len(destinations))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(destinations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = checker.Check(ctx, destinations[index])
+			}
+		}()
+	}
+
+	for index := range destinations {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// probe requests the destination once with HEAD, and with GET when HEAD fails or isn't answered properly
+func (checker *Checker) probe(ctx context.Context, destination string) Result {
+	result := checker.request(ctx, http.MethodHead, destination)
+	if result.Err == nil && result.StatusCode < http.StatusBadRequest {
+		return result
+	}
+	if ctx.Err() != nil {
+		return result
+	}
+	return checker.request(ctx, http.MethodGet, destination)
+}
+
+// request sends a single request and measures its latency
+func (checker *Checker) request(ctx context.Context, method, destination string) Result {
+	if checker.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, checker.Timeout)
+		defer cancel()
+	}
+
+	result := Result{CheckedAt: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, method, destination, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if checker.UserAgent != "" {
+		req.Header.Set("User-Agent", checker.UserAgent)
+	}
+
+	resp, err := checker.Client.Do(req)
+	result.Latency = time.Since(result.CheckedAt)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	io.CopyN(io.Discard, resp.Body, maxDrainSize)
+
+	result.StatusCode = resp.StatusCode
+	return result
+}
+
+// acquireHost waits for a request slot of the host, the returned function releases it
+func (checker *Checker) acquireHost(ctx context.Context, host string) (func(), error) {
+	checker.mu.Lock()
+	if checker.hosts == nil {
+		checker.hosts = make(map[string]*hostSlots)
+	}
+	entry, ok := checker.hosts[host]
+	if !ok {
+		entry = &hostSlots{slots: make(chan struct{}, max(checker.PerHost, 1))}
+		checker.hosts[host] = entry
+	}
+	entry.users++
+	checker.mu.Unlock()
+
+	select {
+	case entry.slots <- struct{}{}:
+		return func() {
+			<-entry.slots
+			checker.releaseHost(host, entry)
+		}, nil
+	case <-ctx.Done():
+		checker.releaseHost(host, entry)
+		return nil, ctx.Err()
+	}
+}
+
+// releaseHost forgets the slots of the host once no request uses or waits for them,
+// so checking many hosts over time doesn't keep an entry for each of them
+func (checker *Checker) releaseHost(host string, entry *hostSlots) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	entry.users--
+	if entry.users == 0 {
+		delete(checker.hosts, host)
+	}
+}
+
+// trackedHosts returns the number of hosts with a request in flight or waiting
+func (checker *Checker) trackedHosts() int {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	return len(checker.hosts)
+}
+
+// isTransient reports whether a failed check may succeed when tried again shortly after
+func isTransient(result Result) bool {
+	if result.Err != nil {
+		return !errors.Is(result.Err, ErrAddressNotAllowed)
+	}
+	switch result.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Backoff returns how long to wait before checking a destination again after its consecutive failures,
+// doubling base with every failure up to limit
+func Backoff(failures int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// NewClient creates the HTTP client of a checker. It refuses to connect to the addresses allow rejects,
+// so checks can't be used to reach the internal network, allow may be nil to connect anywhere.
+// Redirects are followed like browsers do.
+func NewClient(allow func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if allow != nil {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !allow(addr) {
+				return ErrAddressNotAllowed
+			}
+			return nil
+		}
+		// A proxy would be the only address checked
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,286 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestChecker returns a checker using the client of the server, without delays between retries
+func newTestChecker(server *httptest.Server) *Checker {
+	checker := New(server.Client())
+	checker.Timeout = time.Second
+	checker.RetryDelay = time.Millisecond
+	return checker
+}
+
+func TestCheckStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantBroken bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"no content", http.StatusNoContent, false},
+		{"forbidden", http.StatusForbidden, false},
+		{"not found", http.StatusNotFound, true},
+		{"gone", http.StatusGone, true},
+		{"server error", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			result := newTestChecker(server).Check(context.Background(), server.URL)
+			if result.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d", result.StatusCode, tt.status)
+			}
+			if result.Broken() != tt.wantBroken {
+				t.Fatalf("got broken %t, want %t", result.Broken(), tt.wantBroken)
+			}
+			if result.CheckedAt.IsZero() {
+				t.Fatal("check time not recorded")
+			}
+		})
+	}
+}
+
+func TestCheckFallsBackToGet(t *testing.T) {
+	var methods []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	result := newTestChecker(server).Check(context.Background(), server.URL)
+	if result.StatusCode != http.StatusOK || result.Broken() {
+		t.Fatalf("got status %d, err %v, want a healthy 200", result.StatusCode, result.Err)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Fatalf("got methods %v, want HEAD then GET", methods)
+	}
+}
+
+func TestCheckHeadOnly(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+	}))
+	defer server.Close()
+
+	newTestChecker(server).Check(context.Background(), server.URL)
+	if gets.Load() != 0 {
+		t.Fatalf("got %d GET requests after a successful HEAD", gets.Load())
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	checker := newTestChecker(server)
+	checker.Timeout = 50 * time.Millisecond
+	checker.Retries = 0
+
+	start := time.Now()
+	result := checker.Check(context.Background(), server.URL)
+	if result.Err == nil || !result.Broken() {
+		t.Fatalf("got status %d without error, want a timeout", result.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("check took %v, the timeout wasn't applied", elapsed)
+	}
+}
+
+func TestCheckRetriesTransientFailures(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// HEAD and GET of the first attempt are unavailable
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	result := newTestChecker(server).Check(context.Background(), server.URL)
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200 after a retry", result.StatusCode)
+	}
+}
+
+func TestCheckDoesNotRetryPermanentFailures(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	newTestChecker(server).Check(context.Background(), server.URL)
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("got %d requests, want HEAD and GET once", got)
+	}
+}
+
+func TestCheckAllLimitsRequestsPerHost(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	checker := newTestChecker(server)
+	checker.PerHost = 2
+
+	destinations := make([]string, 12)
+	for i := range destinations {
+		destinations[i] = server.URL + "/" + string(rune('a'+i))
+	}
+
+	results := checker.CheckAll(context.Background(), destinations, 8)
+	if len(results) != len(destinations) {
+		t.Fatalf("got %d results, want %d", len(results), len(destinations))
+	}
+	for i, result := range results {
+		if result.StatusCode != http.StatusOK {
+			t.Fatalf("result %d: got status %d, err %v", i, result.StatusCode, result.Err)
+		}
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("got %d concurrent requests to the host, want at most 2", got)
+	}
+}
+
+func TestCheckAllKeepsOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	results := newTestChecker(server).CheckAll(context.Background(), []string{server.URL + "/ok", server.URL + "/missing", "not a url"}, 3)
+	if results[0].Broken() || !results[1].Broken() || results[2].Err == nil {
+		t.Fatalf("got results %+v in the wrong order", results)
+	}
+}
+
+func TestNewClientRefusesAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	checker := New(NewClient(func(addr netip.Addr) bool { return !addr.IsLoopback() }))
+	checker.RetryDelay = time.Millisecond
+
+	result := checker.Check(context.Background(), server.URL)
+	if !errors.Is(result.Err, ErrAddressNotAllowed) {
+		t.Fatalf("got err %v, want ErrAddressNotAllowed", result.Err)
+	}
+
+	result = New(NewClient(nil)).Check(context.Background(), server.URL)
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, err %v without an address filter", result.StatusCode, result.Err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, limit := 15*time.Minute, 24*time.Hour
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 15 * time.Minute},
+		{2, 30 * time.Minute},
+		{4, 2 * time.Hour},
+		{7, 16 * time.Hour},
+		{8, 24 * time.Hour},
+		{100, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.failures, base, limit); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestIdleHostsAreForgotten(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	checker := newTestChecker(server)
+	checker.PerHost = 1
+
+	// Every destination is another host name of the same server
+	destinations := make([]string, 20)
+	for i := range destinations {
+		destinations[i] = fmt.Sprintf("http://host-%d.test/", i)
+	}
+	checker.Client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+
+	for i, result := range checker.CheckAll(context.Background(), destinations, 4) {
+		if result.StatusCode != http.StatusOK {
+			t.Fatalf("result %d: got status %d, err %v", i, result.StatusCode, result.Err)
+		}
+	}
+	if got := checker.trackedHosts(); got != 0 {
+		t.Fatalf("got %d hosts tracked after the checks finished, want 0", got)
+	}
+
+	// A check canceled while waiting for a slot releases the host too
+	release, err := checker.acquireHost(context.Background(), "busy.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := checker.acquireHost(ctx, "busy.test"); err == nil {
+		t.Fatal("got a slot of a busy host with a canceled context")
+	}
+	release()
+	if got := checker.trackedHosts(); got != 0 {
+		t.Fatalf("got %d hosts tracked after the slots were released, want 0", got)
+	}
+}
@@ -0,0 +1,286 @@
+package qrcode
+
+// setFunction sets a module of the function patterns
+func (code *Code) setFunction(x, y int, dark bool) {
+	code.modules[y][x] = dark
+	code.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and reserves the format and version areas
+func (code *Code) drawFunctionPatterns() {
+	size := code.Size
+
+	// Timing patterns
+	for i := 0; i < size; i++ {
+		code.setFunction(6, i, i%2 == 0)
+		code.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns, in three corners
+	code.drawFinderPattern(3, 3)
+	code.drawFinderPattern(size-4, 3)
+	code.drawFinderPattern(3, size-4)
+
+	// Alignment patterns, skipping the ones overlapping the finder patterns
+	positions := alignmentPositions(code.Version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			code.drawAlignmentPattern(x, y)
+		}
+	}
+
+	// Reserve the format bits, drawn for real once the mask is chosen
+	code.drawFormatBits(0)
+	code.drawVersionBits()
+}
+
+// drawFinderPattern draws a finder pattern and its separator centered on x, y
+func (code *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= code.Size || yy < 0 || yy >= code.Size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			code.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws an alignment pattern centered on x, y
+func (code *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			code.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column centers of the alignment patterns of a version
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, version*4+10; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+// drawFormatBits draws both copies of the format information of the level and mask
+func (code *Code) drawFormatBits(mask int) {
+	data := code.Level.formatBits()<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+
+	// First copy, around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		code.setFunction(8, i, bit(bits, i))
+	}
+	code.setFunction(8, 7, bit(bits, 6))
+	code.setFunction(8, 8, bit(bits, 7))
+	code.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		code.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	// Second copy, split between the top right and bottom left finder patterns
+	size := code.Size
+	for i := 0; i < 8; i++ {
+		code.setFunction(size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		code.setFunction(8, size-15+i, bit(bits, i))
+	}
+	code.setFunction(8, size-8, true) // Always dark
+}
+
+// drawVersionBits draws both copies of the version information, only present from version 7
+func (code *Code) drawVersionBits() {
+	if code.Version < 7 {
+		return
+	}
+
+	remainder := code.Version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	bits := code.Version<<12 | remainder
+
+	for i := 0; i < 18; i++ {
+		a, b := code.Size-11+i%3, i/3
+		code.setFunction(a, b, bit(bits, i))
+		code.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a time from the bottom right
+func (code *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for vertical := 0; vertical < code.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vertical
+				if upward {
+					y = code.Size - 1 - vertical
+				}
+				if code.isFunction[y][x] {
+					continue
+				}
+				// Remainder bits past the last codeword stay light
+				if i < len(codewords)*8 {
+					code.modules[y][x] = bit(int(codewords[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the mask pattern
+func (code *Code) applyMask(mask int) {
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				code.modules[y][x] = !code.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan, lower is better
+func (code *Code) penalty() int {
+	size := code.Size
+	result := 0
+
+	// Runs of five or more modules of the same color and finder-like patterns, in rows and columns
+	for _, vertical := range []bool{false, true} {
+		for a := 0; a < size; a++ {
+			at := func(b int) bool {
+				if vertical {
+					return code.modules[b][a]
+				}
+				return code.modules[a][b]
+			}
+
+			run := 0
+			for b := 0; b < size; b++ {
+				if b > 0 && at(b) == at(b-1) {
+					run++
+				} else {
+					if run >= 5 {
+						result += run - 2
+					}
+					run = 1
+				}
+			}
+			if run >= 5 {
+				result += run - 2
+			}
+
+			// Dark-light-dark-dark-dark-light-dark with four light modules on either side,
+			// the area outside the symbol counting as light
+			for b := 0; b+7 <= size; b++ {
+				if matchesFinderLike(at, b, size) {
+					result += 40
+				}
+			}
+		}
+	}
+
+	// Blocks of 2x2 modules of the same color
+	for y := 0; y < size-1; y++ {
+		for x := 0; x < size-1; x++ {
+			dark := code.modules[y][x]
+			if dark == code.modules[y][x+1] && dark == code.modules[y+1][x] && dark == code.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if code.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := size * size
+	result += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+
+	return result
+}
+
+// matchesFinderLike reports whether the line reads 1011101 from start, with four light modules before or after it
+func matchesFinderLike(at func(int) bool, start, size int) bool {
+	get := func(i int) bool {
+		return i >= 0 && i < size && at(i)
+	}
+
+	for i, dark := range [7]bool{true, false, true, true, true, false, true} {
+		if get(start+i) != dark {
+			return false
+		}
+	}
+
+	lightBefore, lightAfter := true, true
+	for i := 1; i <= 4; i++ {
+		lightBefore = lightBefore && !get(start-i)
+		lightAfter = lightAfter && !get(start+6+i)
+	}
+	return lightBefore || lightAfter
+}
+
+// bit reports whether bit i of x is set
+func bit(x, i int) bool {
+	return (x>>i)&1 == 1
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,194 @@
+package qrcode
+
+import (
+	"slices"
+	"testing"
+)
+
+// formatInformation is ISO/IEC 18004 table C.1, the 15 format bits by level and mask, most significant first
+var formatInformation = map[Level][8]string{
+	Low:      {"111011111000100", "111001011110011", "111110110101010", "111100010011101", "110011000101111", "110001100011000", "110110001000001", "110100101110110"},
+	Medium:   {"101010000010010", "101000100100101", "101111001111100", "101101101001011", "100010111111001", "100000011001110", "100111110010111", "100101010100000"},
+	Quartile: {"011010101011111", "011000001101000", "011111100110001", "011101000000110", "010010010110100", "010000110000011", "010111011011010", "010101111101101"},
+	High:     {"001011010001001", "001001110111110", "001110011100111", "001100111010000", "000011101100010", "000001001010101", "000110100001100", "000100000111011"},
+}
+
+// maskConditions are the mask patterns of ISO/IEC 18004 table 10, i is the row and j the column
+var maskConditions = [8]func(i, j int) bool{
+	func(i, j int) bool { return (i+j)%2 == 0 },
+	func(i, j int) bool { return i%2 == 0 },
+	func(i, j int) bool { return j%3 == 0 },
+	func(i, j int) bool { return (i+j)%3 == 0 },
+	func(i, j int) bool { return (i/2+j/3)%2 == 0 },
+	func(i, j int) bool { return i*j%2+i*j%3 == 0 },
+	func(i, j int) bool { return (i*j%2+i*j%3)%2 == 0 },
+	func(i, j int) bool { return ((i*j)%3+(i+j)%2)%2 == 0 },
+}
+
+// readFormatBits reads both copies of the format information, most significant bit first
+func readFormatBits(code *Code) (first, second string) {
+	size := code.Size
+	read := func(positions [][2]int) string {
+		bits := make([]byte, len(positions))
+		for i, position := range positions {
+			bits[i] = '0'
+			if code.Dark(position[0], position[1]) {
+				bits[i] = '1'
+			}
+		}
+		return string(bits)
+	}
+
+	// Row 8 left to right then column 8 upwards, skipping the timing patterns
+	var around [][2]int
+	for x := 0; x <= 8; x++ {
+		if x != 6 {
+			around = append(around, [2]int{x, 8})
+		}
+	}
+	for y := 7; y >= 0; y-- {
+		if y != 6 {
+			around = append(around, [2]int{8, y})
+		}
+	}
+
+	// Column 8 upwards from the bottom, then row 8 left to right on the right
+	var split [][2]int
+	for y := size - 1; y >= size-7; y-- {
+		split = append(split, [2]int{8, y})
+	}
+	for x := size - 8; x < size; x++ {
+		split = append(split, [2]int{x, 8})
+	}
+
+	return read(around), read(split)
+}
+
+func TestFormatInformation(t *testing.T) {
+	for level, masks := range formatInformation {
+		for mask, want := range masks {
+			code := newCode(1, level)
+			code.drawFunctionPatterns()
+			code.drawFormatBits(mask)
+
+			first, second := readFormatBits(code)
+			if first != want || second != want {
+				t.Errorf("level %d mask %d: got format bits %s and %s, want %s", level, mask, first, second, want)
+			}
+		}
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	// ISO/IEC 18004 table D.1, most significant bit first
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{7, "000111110010010100"},
+		{8, "001000010110111100"},
+		{9, "001001101010011001"},
+		{10, "001010010011010011"},
+		{40, "101000110001101001"},
+	}
+
+	for _, tt := range tests {
+		code := newCode(tt.version, Low)
+		code.drawFunctionPatterns()
+
+		// Bit i is in row i/3 and column size-11+i%3 of the top right block, transposed in the bottom left one
+		topRight, bottomLeft := make([]byte, 18), make([]byte, 18)
+		for i := 0; i < 18; i++ {
+			topRight[17-i], bottomLeft[17-i] = '0', '0'
+			if code.Dark(code.Size-11+i%3, i/3) {
+				topRight[17-i] = '1'
+			}
+			if code.Dark(i/3, code.Size-11+i%3) {
+				bottomLeft[17-i] = '1'
+			}
+		}
+		if string(topRight) != tt.want || string(bottomLeft) != tt.want {
+			t.Errorf("version %d: got version bits %s and %s, want %s", tt.version, topRight, bottomLeft, tt.want)
+		}
+	}
+
+	// Versions below 7 have no version information
+	code := newCode(6, Low)
+	code.drawFunctionPatterns()
+	if code.isFunction[0][code.Size-11] {
+		t.Error("version 6 reserves the version information area")
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	// ISO/IEC 18004 table E.1
+	tests := []struct {
+		version int
+		want    []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{6, []int{6, 34}},
+		{7, []int{6, 22, 38}},
+		{10, []int{6, 28, 50}},
+		{15, []int{6, 26, 48, 70}},
+		{16, []int{6, 26, 50, 74}},
+		{22, []int{6, 26, 50, 74, 98}},
+		{27, []int{6, 34, 62, 90, 118}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{36, []int{6, 24, 50, 76, 102, 128, 154}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, tt := range tests {
+		if got := alignmentPositions(tt.version); !slices.Equal(got, tt.want) {
+			t.Errorf("alignmentPositions(%d) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestRawDataModules(t *testing.T) {
+	// Total codewords of ISO/IEC 18004 table 1, counted again from the function patterns drawn
+	tests := []struct {
+		version   int
+		codewords int
+	}{
+		{1, 26}, {2, 44}, {5, 134}, {7, 196}, {10, 346}, {14, 581}, {21, 1156}, {40, 3706},
+	}
+
+	for _, tt := range tests {
+		code := newCode(tt.version, Low)
+		code.drawFunctionPatterns()
+		modules := 0
+		for y := range code.Size {
+			for x := range code.Size {
+				if !code.isFunction[y][x] {
+					modules++
+				}
+			}
+		}
+
+		// The dark module next to the bottom left format bits is drawn with them
+		if got := numRawDataModules(tt.version); got != modules || got/8 != tt.codewords {
+			t.Errorf("version %d: got %d data modules, counted %d, want %d codewords", tt.version, got, modules, tt.codewords)
+		}
+	}
+}
+
+func TestMaskPatterns(t *testing.T) {
+	for mask, condition := range maskConditions {
+		code := newCode(2, Low)
+		code.drawFunctionPatterns()
+		code.applyMask(mask)
+		for y := range code.Size {
+			for x := range code.Size {
+				if code.isFunction[y][x] {
+					continue
+				}
+				if code.Dark(x, y) != condition(y, x) {
+					t.Fatalf("mask %d: module at column %d row %d is %t, want %t", mask, x, y, code.Dark(x, y), condition(y, x))
+				}
+			}
+		}
+	}
+}
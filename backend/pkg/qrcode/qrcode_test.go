@@ -0,0 +1,394 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr error
+	}{
+		{"L", Low, nil},
+		{"m", Medium, nil},
+		{"Q", Quartile, nil},
+		{"h", High, nil},
+		{"", Low, ErrInvalidLevel},
+		{"X", Low, ErrInvalidLevel},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{
+			// ISO/IEC 18004 annex I, "01234567" in a 1-M symbol
+			"01234567 1-M",
+			[]byte{16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17},
+			[]byte{165, 36, 212, 193, 237, 54, 199, 135, 44, 85},
+		},
+		{
+			"HELLO WORLD 1-M",
+			[]byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+	}
+
+	for _, tt := range tests {
+		got := reedSolomonRemainder(tt.data, reedSolomonDivisor(len(tt.want)))
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: got error correction %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeChoosesSmallestVersion(t *testing.T) {
+	// Byte mode capacities of ISO/IEC 18004 table 7
+	tests := []struct {
+		version  int
+		level    Level
+		capacity int
+	}{
+		{1, Low, 17},
+		{1, Medium, 14},
+		{1, Quartile, 11},
+		{1, High, 7},
+		{2, Low, 32},
+		{5, Quartile, 60},
+		{10, Medium, 213},
+		{40, Low, 2953},
+		{40, High, 1273},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(bytes.Repeat([]byte{'a'}, tt.capacity), tt.level)
+		if err != nil || code.Version != tt.version {
+			t.Errorf("%d bytes at level %d: got version %v, %v, want %d", tt.capacity, tt.level, code, err, tt.version)
+			continue
+		}
+
+		code, err = Encode(bytes.Repeat([]byte{'a'}, tt.capacity+1), tt.level)
+		if tt.version == 40 {
+			if !errors.Is(err, ErrTooLong) {
+				t.Errorf("%d bytes at level %d: got %v, want ErrTooLong", tt.capacity+1, tt.level, err)
+			}
+		} else if err != nil || code.Version != tt.version+1 {
+			t.Errorf("%d bytes at level %d: got version %v, %v, want %d", tt.capacity+1, tt.level, code, err, tt.version+1)
+		}
+	}
+}
+
+// blockGroup is a number of error correction blocks with the same number of data codewords
+type blockGroup struct {
+	blocks int
+	data   int
+}
+
+func TestEncodeDecodes(t *testing.T) {
+	// Error correction block structures of ISO/IEC 18004 table 9, the data fills each symbol
+	tests := []struct {
+		version int
+		level   Level
+		ecc     int // Error correction codewords per block
+		groups  []blockGroup
+		length  int
+	}{
+		{1, Low, 7, []blockGroup{{1, 19}}, 17},
+		{1, High, 17, []blockGroup{{1, 9}}, 7},
+		{2, Medium, 16, []blockGroup{{1, 28}}, 26},
+		{5, Quartile, 18, []blockGroup{{2, 15}, {2, 16}}, 60},
+		{7, High, 26, []blockGroup{{4, 13}, {1, 14}}, 64},
+		{10, Medium, 26, []blockGroup{{4, 43}, {1, 44}}, 213},
+		{15, Low, 22, []blockGroup{{5, 87}, {1, 88}}, 520},
+		{40, High, 30, []blockGroup{{20, 15}, {61, 16}}, 1273},
+	}
+
+	for _, tt := range tests {
+		data := []byte(strings.Repeat("https://zipt.io/Ab3?_qr=1 ", tt.length/26+1))[:tt.length]
+		code, err := Encode(data, tt.level)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes, %d) returned error: %v", tt.length, tt.level, err)
+		}
+		if code.Version != tt.version || code.Size != tt.version*4+17 {
+			t.Errorf("%d-%d: got version %d size %d", tt.version, tt.level, code.Version, code.Size)
+			continue
+		}
+
+		checkFunctionPatterns(t, code)
+		got, err := decode(code, tt.ecc, tt.groups)
+		if err != nil {
+			t.Errorf("%d-%d: %v", tt.version, tt.level, err)
+		} else if !bytes.Equal(got, data) {
+			t.Errorf("%d-%d: decoded %q, want %q", tt.version, tt.level, got, data)
+		}
+	}
+}
+
+// checkFunctionPatterns checks the finder and timing patterns and the dark module of a symbol
+func checkFunctionPatterns(t *testing.T, code *Code) {
+	t.Helper()
+	size := code.Size
+
+	// Finder patterns with their light separator
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				ring := max(abs(dx), abs(dy))
+				if want := ring <= 1 || ring == 3; code.Dark(x, y) != want {
+					t.Errorf("version %d: finder module at column %d row %d is %t", code.Version, x, y, code.Dark(x, y))
+				}
+			}
+		}
+	}
+
+	// Timing patterns between the finder patterns
+	for i := 8; i < size-8; i++ {
+		if code.Dark(i, 6) != (i%2 == 0) || code.Dark(6, i) != (i%2 == 0) {
+			t.Errorf("version %d: timing module %d is wrong", code.Version, i)
+		}
+	}
+
+	if !code.Dark(8, size-8) {
+		t.Errorf("version %d: the dark module is light", code.Version)
+	}
+}
+
+// decode reads the data of a byte mode symbol back, written independently of the encoder from ISO/IEC 18004
+// sections 7.7 to 7.9: the format information, the unmasked codewords in placement order, the deinterleaved
+// blocks checked against their error correction codewords, then the segment and its padding.
+func decode(code *Code, ecc int, groups []blockGroup) ([]byte, error) {
+	first, second := readFormatBits(code)
+	if first != second {
+		return nil, errors.New("the format information copies differ")
+	}
+	formats := formatInformation[code.Level]
+	mask := slices.Index(formats[:], first)
+	if mask < 0 {
+		return nil, errors.New("the format information is not the one of the level")
+	}
+
+	// Codewords, from the bottom right in columns of two, alternately upwards and downwards
+	function := functionModules(code.Version)
+	var codewords []byte
+	var current byte
+	bits := 0
+	upward := true
+	for right := code.Size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for k := range code.Size {
+			y := k
+			if upward {
+				y = code.Size - 1 - k
+			}
+			for _, x := range []int{right, right - 1} {
+				if function[y][x] {
+					continue
+				}
+				current <<= 1
+				if code.Dark(x, y) != maskConditions[mask](y, x) {
+					current |= 1
+				}
+				if bits++; bits%8 == 0 {
+					codewords = append(codewords, current)
+				}
+			}
+		}
+		upward = !upward
+	}
+
+	// Deinterleave the blocks and check their error correction, the bits left over are remainder bits
+	total := 0
+	for _, length := range expandGroups(groups) {
+		total += length + ecc
+	}
+	if total != len(codewords) {
+		return nil, errors.New("the block structure doesn't match the symbol capacity")
+	}
+	var data []byte
+	for _, block := range deinterleave(codewords, groups, ecc) {
+		if !validCodeword(block, ecc) {
+			return nil, errors.New("a block fails its error correction check")
+		}
+		data = append(data, block[:len(block)-ecc]...)
+	}
+
+	// Byte mode segment, terminator and pad codewords
+	reader := bitReader{data: data}
+	if mode := reader.read(4); mode != 0b0100 {
+		return nil, errors.New("the segment is not in byte mode")
+	}
+	countBits := 8
+	if code.Version >= 10 {
+		countBits = 16
+	}
+	decoded := make([]byte, reader.read(countBits))
+	for i := range decoded {
+		decoded[i] = byte(reader.read(8))
+	}
+	if reader.read(min(4, len(data)*8-reader.position)) != 0 || reader.read((8-reader.position%8)%8) != 0 {
+		return nil, errors.New("the terminator is not zero")
+	}
+	for pad := byte(0xEC); reader.position < len(data)*8; pad ^= 0xEC ^ 0x11 {
+		if byte(reader.read(8)) != pad {
+			return nil, errors.New("the padding doesn't alternate 0xEC and 0x11")
+		}
+	}
+	return decoded, nil
+}
+
+// expandGroups returns the number of data codewords of every block
+func expandGroups(groups []blockGroup) []int {
+	var lengths []int
+	for _, group := range groups {
+		for range group.blocks {
+			lengths = append(lengths, group.data)
+		}
+	}
+	return lengths
+}
+
+// deinterleave splits the codewords into blocks: the data codewords of the blocks are interleaved first,
+// longer blocks giving their last one after the others, then the error correction codewords
+func deinterleave(codewords []byte, groups []blockGroup, ecc int) [][]byte {
+	lengths := expandGroups(groups)
+	blocks := make([][]byte, len(lengths))
+	next := 0
+	for i := 0; i < slices.Max(lengths); i++ {
+		for j, length := range lengths {
+			if i < length {
+				blocks[j] = append(blocks[j], codewords[next])
+				next++
+			}
+		}
+	}
+	for range ecc {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], codewords[next])
+			next++
+		}
+	}
+	return blocks
+}
+
+// validCodeword reports whether the block is a Reed-Solomon codeword, its polynomial vanishing at the first
+// ecc powers of the primitive element of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func validCodeword(block []byte, ecc int) bool {
+	var exp [255]int
+	value := 1
+	for i := range exp {
+		exp[i] = value
+		value <<= 1
+		if value&0x100 != 0 {
+			value ^= 0x11D
+		}
+	}
+	multiply := func(a, b int) int {
+		if a == 0 || b == 0 {
+			return 0
+		}
+		return exp[(slices.Index(exp[:], a)+slices.Index(exp[:], b))%255]
+	}
+
+	for power := range ecc {
+		syndrome := 0
+		for _, coefficient := range block {
+			syndrome = multiply(syndrome, exp[power]) ^ int(coefficient)
+		}
+		if syndrome != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// functionModules marks the modules of the function patterns and the format and version areas of a version
+func functionModules(version int) [][]bool {
+	size := version*4 + 17
+	function := make([][]bool, size)
+	for y := range function {
+		function[y] = make([]bool, size)
+	}
+	mark := func(x0, y0, width, height int) {
+		for y := y0; y < y0+height; y++ {
+			for x := x0; x < x0+width; x++ {
+				function[y][x] = true
+			}
+		}
+	}
+
+	// Finder patterns with their separators and the format information, including the dark module
+	mark(0, 0, 9, 9)
+	mark(size-8, 0, 8, 9)
+	mark(0, size-8, 9, 8)
+
+	// Timing patterns
+	mark(6, 0, 1, size)
+	mark(0, 6, size, 1)
+
+	// Alignment patterns, except where they would overlap the finder patterns
+	centers := alignmentCenters[version]
+	for _, cy := range centers {
+		for _, cx := range centers {
+			if (cx < 9 && cy < 9) || (cx < 9 && cy > size-9) || (cx > size-9 && cy < 9) {
+				continue
+			}
+			mark(cx-2, cy-2, 5, 5)
+		}
+	}
+
+	if version >= 7 {
+		mark(size-11, 0, 3, 6)
+		mark(0, size-11, 6, 3)
+	}
+	return function
+}
+
+// alignmentCenters are the alignment pattern centers of ISO/IEC 18004 table E.1 for the versions decoded
+var alignmentCenters = map[int][]int{
+	2:  {6, 18},
+	5:  {6, 30},
+	7:  {6, 22, 38},
+	10: {6, 28, 50},
+	15: {6, 26, 48, 70},
+	40: {6, 30, 58, 86, 114, 142, 170},
+}
+
+// bitReader reads bits most significant first
+type bitReader struct {
+	data     []byte
+	position int
+}
+
+// read returns the next length bits, zeros past the end
+func (reader *bitReader) read(length int) int {
+	value := 0
+	for range length {
+		value <<= 1
+		if index := reader.position / 8; index < len(reader.data) && reader.data[index]>>(7-reader.position%8)&1 == 1 {
+			value |= 1
+		}
+		reader.position++
+	}
+	return value
+}
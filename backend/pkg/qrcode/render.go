@@ -0,0 +1,175 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// logoRatio is the share of the symbol width taken by an embedded logo
+const logoRatio = 0.22
+
+// ErrInvalidColor is returned when a color is not a #RGB, #RRGGBB or #RRGGBBAA hex color
+var ErrInvalidColor = errors.New("qrcode: color must be a #RGB, #RRGGBB or #RRGGBBAA hex color")
+
+// Style is how a symbol is rendered
+type Style struct {
+	Size       int         // Width and height of the image in pixels, including the quiet zone
+	Margin     int         // Width of the quiet zone in modules
+	Foreground color.NRGBA // Color of the dark modules
+	Background color.NRGBA // Color of the light modules and the quiet zone
+	Logo       image.Image // Optional, drawn in the center of the symbol on a background pad
+}
+
+// ParseHexColor parses a #RGB, #RRGGBB or #RRGGBBAA color, the # is optional
+func ParseHexColor(value string) (color.NRGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) == 6 {
+		value += "ff"
+	}
+	if len(value) != 8 {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+
+	rgba, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+	return color.NRGBA{R: uint8(rgba >> 24), G: uint8(rgba >> 16), B: uint8(rgba >> 8), A: uint8(rgba)}, nil
+}
+
+// layout places the modules in the image, returning the pixels per module and the offset of the quiet zone,
+// which centers the symbol when the size isn't a multiple of the module count
+func (code *Code) layout(style Style) (scale, offset int) {
+	modules := code.Size + style.Margin*2
+	scale = max(1, style.Size/modules)
+	offset = max(0, (style.Size-scale*modules)/2) + style.Margin*scale
+	return scale, offset
+}
+
+// logoBox returns the square reserved for the logo, in pixels
+func (code *Code) logoBox(scale, offset int) image.Rectangle {
+	symbol := code.Size * scale
+	side := int(float64(symbol) * logoRatio)
+	start := offset + (symbol-side)/2
+	return image.Rect(start, start, start+side, start+side)
+}
+
+// PNG renders the symbol as a PNG image
+func (code *Code) PNG(style Style) ([]byte, error) {
+	scale, offset := code.layout(style)
+	side := max(style.Size, scale*(code.Size+style.Margin*2))
+
+	img := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(img, img.Bounds(), image.NewUniform(style.Background), image.Point{}, draw.Src)
+
+	foreground := image.NewUniform(style.Foreground)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				module := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, module, foreground, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if style.Logo != nil {
+		box := code.logoBox(scale, offset)
+		draw.Draw(img, box, image.NewUniform(style.Background), image.Point{}, draw.Src)
+		logo := fitImage(style.Logo, box.Inset(box.Dx()/10))
+		draw.Draw(img, logo.Bounds(), logo, logo.Bounds().Min, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the symbol as an SVG document, the logo is embedded as a PNG data URI
+func (code *Code) SVG(style Style) ([]byte, error) {
+	scale, offset := code.layout(style)
+	side := max(style.Size, scale*(code.Size+style.Margin*2))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side, side, side)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, side, side, svgFill(style.Background))
+
+	// One path for all dark modules, merging horizontal runs
+	fmt.Fprintf(&buf, `<path %s d="`, svgFill(style.Foreground))
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Dark(x, y) {
+				continue
+			}
+			run := 1
+			for x+run < code.Size && code.Dark(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv%dh-%dz", offset+x*scale, offset+y*scale, run*scale, scale, run*scale)
+			x += run - 1
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if style.Logo != nil {
+		box := code.logoBox(scale, offset)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" %s/>`, box.Min.X, box.Min.Y, box.Dx(), box.Dy(), svgFill(style.Background))
+
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, style.Logo); err != nil {
+			return nil, err
+		}
+		inner := box.Inset(box.Dx() / 10)
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy(), base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// svgFill returns the SVG fill attributes of a color, with its opacity when it isn't opaque
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return fill
+}
+
+// fitImage scales the image to fit the box keeping its aspect ratio, centered in the box.
+// Nearest neighbor scaling is good enough for a logo a few dozen pixels wide.
+func fitImage(src image.Image, box image.Rectangle) image.Image {
+	bounds := src.Bounds()
+	if bounds.Empty() || box.Empty() {
+		return image.NewNRGBA(image.Rectangle{})
+	}
+
+	width, height := box.Dx(), box.Dy()
+	if bounds.Dx()*height > bounds.Dy()*width {
+		height = max(1, bounds.Dy()*width/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*height/bounds.Dy())
+	}
+
+	origin := image.Pt(box.Min.X+(box.Dx()-width)/2, box.Min.Y+(box.Dy()-height)/2)
+	dst := image.NewNRGBA(image.Rectangle{Min: origin, Max: origin.Add(image.Pt(width, height))})
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(origin.X+x, origin.Y+y, src.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	return dst
+}
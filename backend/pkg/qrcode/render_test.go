@@ -0,0 +1,204 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	red   = color.NRGBA{R: 0xff, A: 0xff}
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    color.NRGBA
+		wantErr error
+	}{
+		{"#000000", black, nil},
+		{"fff", white, nil},
+		{"#F00", red, nil},
+		{"#11223380", color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x80}, nil},
+		{"#12345", color.NRGBA{}, ErrInvalidColor},
+		{"#gggggg", color.NRGBA{}, ErrInvalidColor},
+		{"", color.NRGBA{}, ErrInvalidColor},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHexColor(tt.value)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseHexColor(%q) = %v, %v, want %v, %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// encodeTest encodes the data of the render tests
+func encodeTest(t *testing.T) *Code {
+	t.Helper()
+	code, err := Encode([]byte("https://zipt.io/Ab3?_qr=1"), Medium)
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	return code
+}
+
+func TestPNG(t *testing.T) {
+	code := encodeTest(t)
+
+	for _, size := range []int{64, 250, 512} {
+		style := Style{Size: size, Margin: 4, Foreground: black, Background: white}
+		data, err := code.PNG(style)
+		if err != nil {
+			t.Fatalf("PNG(%d) returned error: %v", size, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("PNG(%d) is not a valid PNG: %v", size, err)
+		}
+		if bounds := img.Bounds(); bounds.Dx() != size || bounds.Dy() != size {
+			t.Errorf("PNG(%d) is %dx%d", size, bounds.Dx(), bounds.Dy())
+		}
+
+		// Every module is drawn at its place, the quiet zone around it is light
+		scale, offset := code.layout(style)
+		for y := range code.Size {
+			for x := range code.Size {
+				want := white
+				if code.Dark(x, y) {
+					want = black
+				}
+				if got := color.NRGBAModel.Convert(img.At(offset+x*scale+scale/2, offset+y*scale+scale/2)); got != want {
+					t.Fatalf("PNG(%d): module at column %d row %d is %v, want %v", size, x, y, got, want)
+				}
+			}
+		}
+		if got := color.NRGBAModel.Convert(img.At(offset-1, offset-1)); got != white {
+			t.Errorf("PNG(%d): quiet zone is %v", size, got)
+		}
+	}
+}
+
+func TestPNGLogo(t *testing.T) {
+	code := encodeTest(t)
+	logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := range 20 {
+		for x := range 40 {
+			logo.Set(x, y, red)
+		}
+	}
+
+	style := Style{Size: 300, Margin: 4, Foreground: black, Background: white, Logo: logo}
+	data, err := code.PNG(style)
+	if err != nil {
+		t.Fatalf("PNG returned error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PNG is not a valid PNG: %v", err)
+	}
+
+	// The logo is centered on a light pad, keeping its aspect ratio
+	box := code.logoBox(code.layout(style))
+	center := image.Pt((box.Min.X+box.Max.X)/2, (box.Min.Y+box.Max.Y)/2)
+	if got := color.NRGBAModel.Convert(img.At(center.X, center.Y)); got != red {
+		t.Errorf("center is %v, want the logo", got)
+	}
+	if got := color.NRGBAModel.Convert(img.At(center.X, box.Min.Y+1)); got != white {
+		t.Errorf("pad above the wide logo is %v, want the background", got)
+	}
+}
+
+// svgDocument is the part of a rendered SVG the tests read
+type svgDocument struct {
+	Width   int    `xml:"width,attr"`
+	Height  int    `xml:"height,attr"`
+	ViewBox string `xml:"viewBox,attr"`
+	Rects   []struct {
+		Fill string `xml:"fill,attr"`
+	} `xml:"rect"`
+	Path struct {
+		Fill        string `xml:"fill,attr"`
+		FillOpacity string `xml:"fill-opacity,attr"`
+		D           string `xml:"d,attr"`
+	} `xml:"path"`
+	Images []struct {
+		Href string `xml:"href,attr"`
+	} `xml:"image"`
+}
+
+func TestSVG(t *testing.T) {
+	code := encodeTest(t)
+	style := Style{Size: 250, Margin: 2, Foreground: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x80}, Background: white}
+
+	data, err := code.SVG(style)
+	if err != nil {
+		t.Fatalf("SVG returned error: %v", err)
+	}
+	var doc svgDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("SVG is not valid XML: %v", err)
+	}
+
+	if doc.Width != 250 || doc.Height != 250 || doc.ViewBox != "0 0 250 250" {
+		t.Errorf("got width %d height %d viewBox %q, want 250", doc.Width, doc.Height, doc.ViewBox)
+	}
+	if len(doc.Rects) != 1 || doc.Rects[0].Fill != "#ffffff" {
+		t.Errorf("got background %+v, want one #ffffff rect", doc.Rects)
+	}
+	if doc.Path.Fill != "#112233" || doc.Path.FillOpacity != "0.502" {
+		t.Errorf("got foreground %q opacity %q, want #112233 at 0.502", doc.Path.Fill, doc.Path.FillOpacity)
+	}
+
+	// Draw the runs of the path back into modules
+	scale, offset := code.layout(style)
+	dark := make([][]bool, code.Size)
+	for y := range dark {
+		dark[y] = make([]bool, code.Size)
+	}
+	for _, run := range strings.Split(strings.TrimSuffix(doc.Path.D, "z"), "z") {
+		var x, y, width, height, back int
+		if _, err := fmt.Sscanf(run, "M%d %dh%dv%dh-%d", &x, &y, &width, &height, &back); err != nil {
+			t.Fatalf("path segment %q: %v", run, err)
+		}
+		if height != scale || back != width || (x-offset)%scale != 0 || (y-offset)%scale != 0 {
+			t.Fatalf("path segment %q is not a run of modules", run)
+		}
+		for i := range width / scale {
+			dark[(y-offset)/scale][(x-offset)/scale+i] = true
+		}
+	}
+	for y := range code.Size {
+		for x := range code.Size {
+			if dark[y][x] != code.Dark(x, y) {
+				t.Fatalf("module at column %d row %d is %t, want %t", x, y, dark[y][x], code.Dark(x, y))
+			}
+		}
+	}
+}
+
+func TestSVGLogo(t *testing.T) {
+	code := encodeTest(t)
+	logo := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+
+	data, err := code.SVG(Style{Size: 300, Margin: 4, Foreground: black, Background: white, Logo: logo})
+	if err != nil {
+		t.Fatalf("SVG returned error: %v", err)
+	}
+	var doc svgDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("SVG is not valid XML: %v", err)
+	}
+
+	if len(doc.Rects) != 2 || len(doc.Images) != 1 || !strings.HasPrefix(doc.Images[0].Href, "data:image/png;base64,") {
+		t.Errorf("got %d rects and images %+v, want a pad and an embedded PNG", len(doc.Rects), doc.Images)
+	}
+}
@@ -27,6 +27,12 @@ func init() {
 	StaticBucket = os.Getenv("S3_STATIC_BUCKET")
 	StaticBucketUrl = os.Getenv("S3_STATIC_BUCKET_BASEURL")
 
+	// Object storage is optional, without an endpoint the client stays nil
+	if os.Getenv("S3_ENDPOINT") == "" {
+		logger.Log.Info("S3_ENDPOINT is not set, object storage is disabled")
+		return
+	}
+
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(os.Getenv("S3_ACCESS_KEY_ID"), os.Getenv("S3_SECRET_KEY"), "")),
 		config.WithRegion("auto"),
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ErrDisabled is returned when object storage is used without S3 being configured
+var ErrDisabled = errors.New("object storage is disabled")
+
+// Enabled reports whether object storage is configured
+func Enabled() bool {
+	return Client != nil
+}
+
+// GetObject downloads an object of the static bucket
+func GetObject(ctx context.Context, key string) ([]byte, error) {
+	if !Enabled() {
+		return nil, ErrDisabled
+	}
+
+	output, err := Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(StaticBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
+// PutObject uploads an object to the static bucket
+func PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	if !Enabled() {
+		return ErrDisabled
+	}
+
+	_, err := Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(StaticBucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// DeleteObject deletes an object of the static bucket
+func DeleteObject(ctx context.Context, key string) error {
+	if !Enabled() {
+		return ErrDisabled
+	}
+
+	_, err := Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(StaticBucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// ObjectURL returns the public URL of an object of the static bucket
+func ObjectURL(key string) string {
+	return strings.TrimSuffix(StaticBucketUrl, "/") + "/" + key
+}
+
+// ObjectKey returns the key of an object from its public URL, false when the URL is not in the static bucket
+func ObjectKey(url string) (string, bool) {
+	prefix := strings.TrimSuffix(StaticBucketUrl, "/") + "/"
+	if StaticBucketUrl == "" || !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
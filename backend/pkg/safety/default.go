@@ -0,0 +1,61 @@
+package safety
+
+import (
+	"context"
+	"net/netip"
+	"os"
+
+	"github.com/yorukot/zipt/pkg/logger"
+)
+
+// Default is the guard of the instance, configured by Init
+var Default = NewGuard(nil, NewPrivateAddressChecker())
+
+// allowPrivate is set when destinations on private networks are allowed
+var allowPrivate bool
+
+// Init configures the default guard from the environment:
+// SAFETY_BLOCKED_DOMAINS_FILE, SAFETY_BLOCKED_PATTERNS_FILE and SAFETY_THREAT_HOSTS_FILE are the list files,
+// SAFETY_ALLOW_PRIVATE_DESTINATIONS=true allows destinations on private networks, e.g. for intranet instances.
+func Init() {
+	allowPrivate = os.Getenv("SAFETY_ALLOW_PRIVATE_DESTINATIONS") == "true"
+	if allowPrivate {
+		Default = NewGuard(nil, nil)
+	}
+
+	if _, err := Reload(); err != nil {
+		logger.Log.Sugar().Warnf("Could not load the destination blocklists: %v", err)
+	}
+}
+
+// Reload reads the list files of the default guard again, it reports whether the lists changed.
+// The previous lists are kept when a file can't be read.
+func Reload() (bool, error) {
+	lists, err := LoadLists(ListFiles{
+		Domains:     os.Getenv("SAFETY_BLOCKED_DOMAINS_FILE"),
+		Patterns:    os.Getenv("SAFETY_BLOCKED_PATTERNS_FILE"),
+		ThreatHosts: os.Getenv("SAFETY_THREAT_HOSTS_FILE"),
+	})
+	if err != nil {
+		return false, err
+	}
+	return Default.SetLists(lists), nil
+}
+
+// AddressFilter returns which addresses requests to destinations may connect to, nil when every address is allowed
+func AddressFilter() func(netip.Addr) bool {
+	if allowPrivate {
+		return nil
+	}
+	return IsPublicAddr
+}
+
+// Check runs every check of the default guard on the destination, nil means it is allowed
+func Check(ctx context.Context, destination string) *Violation {
+	return Default.Check(ctx, destination)
+}
+
+// CheckLists checks the destination against the lists of the default guard
+func CheckLists(destination string) *Violation {
+	return Default.CheckLists(destination)
+}
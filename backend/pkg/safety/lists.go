@@ -0,0 +1,190 @@
+package safety
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/netip"
+	neturl "net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lists are the instance wide blocklists, loaded from plain text files with one entry per line.
+// Empty lines and lines starting with # are ignored.
+type Lists struct {
+	domains     map[string]bool  // Blocked domains, their subdomains are blocked too
+	patterns    []*regexp.Regexp // Matched against the whole destination URL
+	threatHosts map[string]bool  // Known phishing and malware hosts, matched exactly
+	version     string
+}
+
+// ListFiles are the paths of the list files, an empty path is an empty list
+type ListFiles struct {
+	Domains     string
+	Patterns    string
+	ThreatHosts string
+}
+
+// LoadLists reads the list files
+func LoadLists(files ListFiles) (*Lists, error) {
+	domains, err := readListFile(files.Domains)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := readListFile(files.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	threatHosts, err := readListFile(files.ThreatHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLists(domains, patterns, threatHosts)
+}
+
+// NewLists builds the lists from their entries.
+// Domains may be written as example.com, *.example.com or a URL, threat hosts may use the hosts file format.
+func NewLists(domains, patterns, threatHosts []string) (*Lists, error) {
+	lists := &Lists{
+		domains:     make(map[string]bool, len(domains)),
+		threatHosts: make(map[string]bool, len(threatHosts)),
+	}
+
+	for _, entry := range domains {
+		if host := entryHost(strings.TrimPrefix(entry, "*.")); host != "" {
+			lists.domains[host] = true
+		}
+	}
+
+	for _, entry := range patterns {
+		pattern, err := regexp.Compile(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocked pattern %q: %w", entry, err)
+		}
+		lists.patterns = append(lists.patterns, pattern)
+	}
+
+	for _, entry := range threatHosts {
+		// Hosts files put the address first, like 0.0.0.0 phishing.example, and may end with a comment
+		entry, _, _ = strings.Cut(entry, "#")
+		fields := strings.Fields(entry)
+		if len(fields) > 1 {
+			if _, err := netip.ParseAddr(fields[0]); err == nil {
+				fields = fields[1:]
+			}
+		}
+		for _, field := range fields {
+			if host := entryHost(field); host != "" {
+				lists.threatHosts[host] = true
+			}
+		}
+	}
+
+	lists.version = listsVersion(lists.domains, patterns, lists.threatHosts)
+	return lists, nil
+}
+
+// Version identifies the content of the lists, it changes whenever an entry is added or removed
+func (lists *Lists) Version() string {
+	return lists.version
+}
+
+// Check checks the destination against the lists
+func (lists *Lists) Check(_ context.Context, destination *neturl.URL) *Violation {
+	host := normalizeHost(destination.Hostname())
+
+	for _, threat := range []string{host, strings.TrimPrefix(host, "www.")} {
+		if lists.threatHosts[threat] {
+			return &Violation{Reason: ReasonThreat, Rule: threat}
+		}
+	}
+
+	// The domain itself and every parent domain
+	for domain := host; domain != ""; {
+		if lists.domains[domain] {
+			return &Violation{Reason: ReasonBlockedDomain, Rule: domain}
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+
+	full := destination.String()
+	for _, pattern := range lists.patterns {
+		if pattern.MatchString(full) {
+			return &Violation{Reason: ReasonBlockedPattern, Rule: pattern.String()}
+		}
+	}
+
+	return nil
+}
+
+// entryHost returns the host of a domain list entry, which may also be a URL
+func entryHost(entry string) string {
+	if strings.Contains(entry, "://") {
+		if parsed, err := neturl.Parse(entry); err == nil {
+			entry = parsed.Hostname()
+		}
+	}
+	return normalizeHost(entry)
+}
+
+// readListFile reads the entries of a list file
+func readListFile(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readList(file)
+}
+
+// readList reads the non empty, non comment lines of a list
+func readList(reader io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// listsVersion hashes the sorted entries of the lists
+func listsVersion(domains map[string]bool, patterns []string, threatHosts map[string]bool) string {
+	hash := sha256.New()
+	for _, part := range [][]string{sortedKeys(domains), patterns, sortedKeys(threatHosts)} {
+		for _, entry := range part {
+			io.WriteString(hash, entry+"\n")
+		}
+		io.WriteString(hash, "\x00")
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
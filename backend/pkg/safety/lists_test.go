@@ -0,0 +1,137 @@
+package safety
+
+import (
+	"context"
+	neturl "net/url"
+	"strings"
+	"testing"
+)
+
+// checkLists checks a destination against the lists
+func checkLists(t *testing.T, lists *Lists, destination string) *Violation {
+	t.Helper()
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) returned error: %v", destination, err)
+	}
+	return lists.Check(context.Background(), parsed)
+}
+
+func TestBlockedDomains(t *testing.T) {
+	lists, err := NewLists([]string{"example.com", "*.wildcard.test", "https://Phish.Example.ORG/login", "dotted.test."}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewLists returned error: %v", err)
+	}
+
+	tests := []struct {
+		destination string
+		want        string // Matched entry, empty when allowed
+	}{
+		{"https://example.com/", "example.com"},
+		{"https://EXAMPLE.com/path", "example.com"},
+		{"https://example.com./", "example.com"},
+		{"https://www.example.com/", "example.com"},
+		{"https://a.b.example.com:8443/", "example.com"},
+		{"https://notexample.com/", ""},
+		{"https://example.com.evil.test/", ""},
+		{"https://wildcard.test/", "wildcard.test"},
+		{"https://sub.wildcard.test/", "wildcard.test"},
+		{"https://phish.example.org/", "phish.example.org"},
+		{"https://example.org/", ""},
+		{"https://dotted.test/", "dotted.test"},
+		{"https://x.dotted.test./", "dotted.test"},
+	}
+
+	for _, tt := range tests {
+		violation := checkLists(t, lists, tt.destination)
+		switch {
+		case tt.want == "" && violation != nil:
+			t.Errorf("Check(%q) = %v, want allowed", tt.destination, violation)
+		case tt.want != "" && (violation == nil || violation.Reason != ReasonBlockedDomain || violation.Rule != tt.want):
+			t.Errorf("Check(%q) = %v, want blocked by %q", tt.destination, violation, tt.want)
+		}
+	}
+}
+
+func TestThreatHosts(t *testing.T) {
+	entries, err := readList(strings.NewReader(`# Hosts file
+0.0.0.0 phishing.example
+127.0.0.1	malware.example   # inline comment
+
+0.0.0.0 one.example two.example
+Bare.Example.
+`))
+	if err != nil {
+		t.Fatalf("readList returned error: %v", err)
+	}
+	lists, err := NewLists(nil, nil, append(entries, "   "))
+	if err != nil {
+		t.Fatalf("NewLists returned error: %v", err)
+	}
+
+	tests := []struct {
+		destination string
+		want        string
+	}{
+		{"https://phishing.example/", "phishing.example"},
+		{"https://www.phishing.example/", "phishing.example"},
+		{"https://malware.example./x", "malware.example"},
+		{"https://one.example/", "one.example"},
+		{"https://two.example/", "two.example"},
+		{"https://bare.example/", "bare.example"},
+		{"https://sub.phishing.example/", ""}, // Threat hosts are matched exactly
+		{"https://comment/", ""},
+		{"https://0.0.0.0/", ""},
+	}
+
+	for _, tt := range tests {
+		violation := checkLists(t, lists, tt.destination)
+		switch {
+		case tt.want == "" && violation != nil:
+			t.Errorf("Check(%q) = %v, want allowed", tt.destination, violation)
+		case tt.want != "" && (violation == nil || violation.Reason != ReasonThreat || violation.Rule != tt.want):
+			t.Errorf("Check(%q) = %v, want a threat on %q", tt.destination, violation, tt.want)
+		}
+	}
+}
+
+func TestBlockedPatterns(t *testing.T) {
+	lists, err := NewLists(nil, []string{`^https?://[^/]+/wp-login\.php`, `(?i)free-gift`}, nil)
+	if err != nil {
+		t.Fatalf("NewLists returned error: %v", err)
+	}
+
+	tests := []struct {
+		destination string
+		blocked     bool
+	}{
+		{"https://site.test/wp-login.php?x=1", true},
+		{"https://site.test/blog/wp-login.php", false},
+		{"https://site.test/FREE-GIFT", true},
+		{"https://site.test/", false},
+	}
+
+	for _, tt := range tests {
+		violation := checkLists(t, lists, tt.destination)
+		if got := violation != nil && violation.Reason == ReasonBlockedPattern; got != tt.blocked {
+			t.Errorf("Check(%q) = %v, want blocked %t", tt.destination, violation, tt.blocked)
+		}
+	}
+
+	if _, err := NewLists(nil, []string{"("}, nil); err == nil {
+		t.Error("NewLists accepted an invalid pattern")
+	}
+}
+
+func TestListsVersion(t *testing.T) {
+	first, _ := NewLists([]string{"a.test", "b.test"}, nil, []string{"0.0.0.0 c.test"})
+	reordered, _ := NewLists([]string{"B.test", "*.a.test"}, nil, []string{"c.test"})
+	changed, _ := NewLists([]string{"a.test"}, nil, []string{"c.test"})
+
+	if first.Version() != reordered.Version() {
+		t.Error("the version changed with the order or the spelling of the entries")
+	}
+	if first.Version() == changed.Version() {
+		t.Error("the version didn't change when an entry was removed")
+	}
+}
@@ -0,0 +1,99 @@
+package safety
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// resolveTimeout bounds the DNS lookup of a destination host
+const resolveTimeout = 2 * time.Second
+
+// internalSuffixes are host name suffixes that only resolve inside a private network
+var internalSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+// Resolver looks up the addresses of a host
+type Resolver func(ctx context.Context, network, host string) ([]netip.Addr, error)
+
+// PrivateAddressChecker rejects destinations on loopback, private, link local and other non public addresses.
+// Host names are resolved when a resolver is set, a host that doesn't resolve is allowed since it can't be reached either.
+type PrivateAddressChecker struct {
+	Resolve Resolver
+}
+
+// NewPrivateAddressChecker creates a checker resolving host names with the system resolver
+func NewPrivateAddressChecker() *PrivateAddressChecker {
+	return &PrivateAddressChecker{Resolve: net.DefaultResolver.LookupNetIP}
+}
+
+// Check rejects the destination when its host is, or resolves to, a non public address
+func (checker *PrivateAddressChecker) Check(ctx context.Context, destination *neturl.URL) *Violation {
+	host := normalizeHost(destination.Hostname())
+
+	if host == "localhost" {
+		return &Violation{Reason: ReasonPrivateAddress, Rule: host}
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return &Violation{Reason: ReasonPrivateAddress, Rule: host}
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(addr) {
+			return &Violation{Reason: ReasonPrivateAddress, Rule: addr.String()}
+		}
+		return nil
+	}
+
+	if checker.Resolve == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	addrs, err := checker.Resolve(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return &Violation{Reason: ReasonPrivateAddress, Rule: host + " (" + addr.String() + ")"}
+		}
+	}
+	return nil
+}
+
+// IsPublicAddr reports whether the address is reachable on the public internet
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	// Carrier grade NAT, benchmarking and the documentation ranges aren't covered by the methods above
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedPrefixes are the other special purpose ranges that never host a public destination
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
@@ -0,0 +1,133 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	neturl "net/url"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.255.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"172.32.0.1", true},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Cloud metadata endpoints
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false}, // Carrier grade NAT
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"192.0.0.8", false},
+		{"192.0.2.1", false},
+		{"198.18.0.1", false},
+		{"198.51.100.1", false},
+		{"203.0.113.1", false},
+		{"224.0.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"::", false},
+		{"fc00::1", false},
+		{"fd12:3456::1", false},
+		{"fe80::1", false},
+		{"ff02::1", false},
+		{"2001:db8::1", false},
+		{"::ffff:127.0.0.1", false}, // IPv4-mapped IPv6
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:100.64.0.1", false},
+		{"::ffff:93.184.216.34", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("IsPublicAddr(%s) = %t, want %t", tt.addr, got, tt.public)
+		}
+	}
+}
+
+// stubResolver resolves hosts from a map, other hosts fail to resolve
+func stubResolver(hosts map[string][]string) Resolver {
+	return func(_ context.Context, network, host string) ([]netip.Addr, error) {
+		addresses, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		addrs := make([]netip.Addr, len(addresses))
+		for i, address := range addresses {
+			addrs[i] = netip.MustParseAddr(address)
+		}
+		return addrs, nil
+	}
+}
+
+func TestPrivateAddressChecker(t *testing.T) {
+	checker := &PrivateAddressChecker{Resolve: stubResolver(map[string][]string{
+		"public.test":   {"93.184.216.34"},
+		"rebind.test":   {"127.0.0.1"},
+		"metadata.test": {"169.254.169.254"},
+		"mixed.test":    {"93.184.216.34", "10.0.0.5"},
+		"mapped.test":   {"::ffff:192.168.0.10"},
+		"v6.test":       {"2606:4700::1111"},
+	})}
+
+	tests := []struct {
+		destination string
+		want        string // Rule of the violation, empty when allowed
+	}{
+		{"https://public.test/", ""},
+		{"https://v6.test/", ""},
+		{"https://unresolved.test/", ""},
+		{"https://rebind.test/", "rebind.test (127.0.0.1)"},
+		{"https://metadata.test/latest", "metadata.test (169.254.169.254)"},
+		{"https://mixed.test/", "mixed.test (10.0.0.5)"},
+		{"https://mapped.test/", "mapped.test (::ffff:192.168.0.10)"},
+		{"http://localhost:8080/", "localhost"},
+		{"http://LOCALHOST./", "localhost"},
+		{"http://app.localhost/", "app.localhost"},
+		{"http://printer.local/", "printer.local"},
+		{"http://db.internal/", "db.internal"},
+		{"http://metadata.google.internal./", "metadata.google.internal"},
+		{"http://nas.lan/", "nas.lan"},
+		{"http://router.home.arpa/", "router.home.arpa"},
+		{"http://internal.test/", ""},
+		{"http://127.0.0.1/", "127.0.0.1"},
+		{"http://[::1]:3000/", "::1"},
+		{"http://[::ffff:127.0.0.1]/", "::ffff:127.0.0.1"},
+		{"http://100.64.1.1/", "100.64.1.1"},
+		{"http://93.184.216.34/", ""},
+	}
+
+	for _, tt := range tests {
+		parsed, err := neturl.Parse(tt.destination)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) returned error: %v", tt.destination, err)
+		}
+		violation := checker.Check(context.Background(), parsed)
+		switch {
+		case tt.want == "" && violation != nil:
+			t.Errorf("Check(%q) = %v, want allowed", tt.destination, violation)
+		case tt.want != "" && (violation == nil || violation.Reason != ReasonPrivateAddress || violation.Rule != tt.want):
+			t.Errorf("Check(%q) = %v, want a private address on %q", tt.destination, violation, tt.want)
+		}
+	}
+}
+
+func TestPrivateAddressCheckerWithoutResolver(t *testing.T) {
+	checker := &PrivateAddressChecker{}
+	parsed, _ := neturl.Parse("https://rebind.test/")
+	if violation := checker.Check(context.Background(), parsed); violation != nil {
+		t.Errorf("Check without a resolver = %v, want host names allowed", violation)
+	}
+}
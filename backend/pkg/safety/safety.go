@@ -0,0 +1,139 @@
+// Package safety decides whether a destination URL may be shortened. Destinations are checked against
+// instance wide domain and pattern blocklists, a local list of phishing and malware hosts, and are
+// rejected when they point at private or loopback addresses. More checks can be plugged in with Register.
+package safety
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"sync"
+)
+
+// Reasons a destination is rejected
+const (
+	ReasonInvalid        = "invalid_url"
+	ReasonBlockedDomain  = "blocked_domain"
+	ReasonBlockedPattern = "blocked_pattern"
+	ReasonThreat         = "threat"
+	ReasonPrivateAddress = "private_address"
+)
+
+// Violation is why a destination was rejected
+type Violation struct {
+	Reason string // One of the Reason constants, or the reason of a registered checker
+	Rule   string // The list entry or address that matched
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("destination rejected (%s): %s", v.Reason, v.Rule)
+}
+
+// Checker is a single destination check
+type Checker interface {
+	// Check returns the violation of the destination, nil when it is allowed
+	Check(ctx context.Context, destination *neturl.URL) *Violation
+}
+
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func(ctx context.Context, destination *neturl.URL) *Violation
+
+// Check calls the function
+func (f CheckerFunc) Check(ctx context.Context, destination *neturl.URL) *Violation {
+	return f(ctx, destination)
+}
+
+// Guard holds the current lists and the checkers destinations go through
+type Guard struct {
+	mu       sync.RWMutex
+	lists    *Lists
+	network  Checker   // Address checks, they don't depend on the lists
+	checkers []Checker // Registered checks
+}
+
+// NewGuard creates a guard with the lists, network is the address check and may be nil to allow every address
+func NewGuard(lists *Lists, network Checker) *Guard {
+	if lists == nil {
+		lists = &Lists{}
+	}
+	return &Guard{lists: lists, network: network}
+}
+
+// Register adds a check run on every destination after the built in ones
+func (guard *Guard) Register(checker Checker) {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.checkers = append(guard.checkers, checker)
+}
+
+// SetLists replaces the lists, it reports whether their content changed
+func (guard *Guard) SetLists(lists *Lists) bool {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	changed := guard.lists.Version() != lists.Version()
+	guard.lists = lists
+	return changed
+}
+
+// Version identifies the content of the current lists
+func (guard *Guard) Version() string {
+	guard.mu.RLock()
+	defer guard.mu.RUnlock()
+	return guard.lists.Version()
+}
+
+// Check runs every check on the destination, nil means it is allowed
+func (guard *Guard) Check(ctx context.Context, destination string) *Violation {
+	parsed, violation := parseDestination(destination)
+	if violation != nil {
+		return violation
+	}
+
+	guard.mu.RLock()
+	lists, network, checkers := guard.lists, guard.network, guard.checkers
+	guard.mu.RUnlock()
+
+	if violation := lists.Check(ctx, parsed); violation != nil {
+		return violation
+	}
+	if network != nil {
+		if violation := network.Check(ctx, parsed); violation != nil {
+			return violation
+		}
+	}
+	for _, checker := range checkers {
+		if violation := checker.Check(ctx, parsed); violation != nil {
+			return violation
+		}
+	}
+	return nil
+}
+
+// CheckLists only checks the destination against the lists, this is what changes when they are reloaded
+func (guard *Guard) CheckLists(destination string) *Violation {
+	parsed, violation := parseDestination(destination)
+	if violation != nil {
+		return violation
+	}
+
+	guard.mu.RLock()
+	lists := guard.lists
+	guard.mu.RUnlock()
+
+	return lists.Check(context.Background(), parsed)
+}
+
+// parseDestination parses an absolute destination URL, hosts are compared in lowercase without the trailing dot
+func parseDestination(destination string) (*neturl.URL, *Violation) {
+	parsed, err := neturl.Parse(strings.TrimSpace(destination))
+	if err != nil || parsed.Hostname() == "" {
+		return nil, &Violation{Reason: ReasonInvalid, Rule: destination}
+	}
+	return parsed, nil
+}
+
+// normalizeHost lowercases a host name and removes its trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
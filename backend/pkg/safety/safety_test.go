@@ -0,0 +1,74 @@
+package safety
+
+import (
+	"context"
+	neturl "net/url"
+	"testing"
+)
+
+func TestGuardCheck(t *testing.T) {
+	lists, err := NewLists([]string{"blocked.test"}, nil, []string{"0.0.0.0 threat.test"})
+	if err != nil {
+		t.Fatalf("NewLists returned error: %v", err)
+	}
+	guard := NewGuard(lists, &PrivateAddressChecker{Resolve: stubResolver(map[string][]string{
+		"private.test": {"192.168.1.20"},
+	})})
+	guard.Register(CheckerFunc(func(_ context.Context, destination *neturl.URL) *Violation {
+		if destination.Scheme != "https" {
+			return &Violation{Reason: "insecure", Rule: destination.Scheme}
+		}
+		return nil
+	}))
+
+	tests := []struct {
+		destination string
+		reason      string // Empty when allowed
+	}{
+		{"https://allowed.test/", ""},
+		{"  https://allowed.test/  ", ""},
+		{"https://www.blocked.test/", ReasonBlockedDomain},
+		{"https://threat.test/", ReasonThreat},
+		{"https://private.test/", ReasonPrivateAddress},
+		{"https://10.0.0.1/", ReasonPrivateAddress},
+		{"http://allowed.test/", "insecure"},
+		{"not a url", ReasonInvalid},
+		{"/relative/path", ReasonInvalid},
+	}
+
+	for _, tt := range tests {
+		violation := guard.Check(context.Background(), tt.destination)
+		if got := ""; violation != nil {
+			got = violation.Reason
+			if got != tt.reason {
+				t.Errorf("Check(%q) = %v, want reason %q", tt.destination, violation, tt.reason)
+			}
+		} else if tt.reason != "" {
+			t.Errorf("Check(%q) allowed, want reason %q", tt.destination, tt.reason)
+		}
+	}
+
+	// Only the lists are checked again when they change
+	if violation := guard.CheckLists("https://private.test/"); violation != nil {
+		t.Errorf("CheckLists(private.test) = %v, want the address check skipped", violation)
+	}
+}
+
+func TestGuardSetLists(t *testing.T) {
+	guard := NewGuard(nil, nil)
+	if violation := guard.CheckLists("https://late.test/"); violation != nil {
+		t.Fatalf("CheckLists with empty lists = %v", violation)
+	}
+
+	lists, _ := NewLists([]string{"late.test"}, nil, nil)
+	if !guard.SetLists(lists) {
+		t.Error("SetLists reported no change for new entries")
+	}
+	same, _ := NewLists([]string{"LATE.test."}, nil, nil)
+	if guard.SetLists(same) {
+		t.Error("SetLists reported a change for the same entries")
+	}
+	if violation := guard.CheckLists("https://late.test/"); violation == nil || violation.Reason != ReasonBlockedDomain {
+		t.Errorf("CheckLists(late.test) = %v, want it blocked by the new lists", violation)
+	}
+}
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Clock returns the current time, tests replace it with a fixed one
+type Clock func() time.Time
+
+// Errors returned when parsing a window
+var (
+	ErrInvalidDay      = errors.New("invalid day of week")
+	ErrInvalidTime     = errors.New("invalid time of day, expected HH:MM")
+	ErrInvalidTimezone = errors.New("invalid IANA timezone")
+	ErrEmptyWindow     = errors.New("window start and end must differ")
+)
+
+// dayNames maps the short day names used by the API to weekdays
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring time-of-day window on some days of the week in a timezone.
+// A window whose end is before its start runs past midnight into the next day.
+type Window struct {
+	Days     [7]bool
+	Start    time.Duration // Offset from midnight
+	End      time.Duration // Offset from midnight
+	Location *time.Location
+}
+
+// ParseWindow builds a window from day names (mon..sun), HH:MM times and an IANA timezone
+func ParseWindow(days []string, start, end, timezone string) (Window, error) {
+	var window Window
+
+	if len(days) == 0 {
+		return window, ErrInvalidDay
+	}
+	for _, day := range days {
+		weekday, ok := dayNames[strings.ToLower(day)]
+		if !ok {
+			return window, fmt.Errorf("%w: %s", ErrInvalidDay, day)
+		}
+		window.Days[weekday] = true
+	}
+
+	var err error
+	if window.Start, err = parseTimeOfDay(start); err != nil {
+		return window, err
+	}
+	if window.End, err = parseTimeOfDay(end); err != nil {
+		return window, err
+	}
+	if window.Start == window.End {
+		return window, ErrEmptyWindow
+	}
+
+	// time.LoadLocation treats an empty name as UTC, require it explicitly
+	if timezone == "" {
+		return window, ErrInvalidTimezone
+	}
+	if window.Location, err = time.LoadLocation(timezone); err != nil {
+		return window, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+	}
+
+	return window, nil
+}
+
+// parseTimeOfDay parses HH:MM into an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTime, value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window, using the wall clock of the window timezone
+func (w Window) Contains(t time.Time) bool {
+	location := w.Location
+	if location == nil {
+		location = time.UTC
+	}
+	local := t.In(location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	day := local.Weekday()
+
+	if w.Start < w.End {
+		return w.Days[day] && offset >= w.Start && offset < w.End
+	}
+
+	// Overnight window, the part after midnight belongs to the previous day
+	previous := (day + 6) % 7
+	return (w.Days[day] && offset >= w.Start) || (w.Days[previous] && offset < w.End)
+}
+
+// Evaluator matches windows against the time returned by its clock
+type Evaluator struct {
+	Now Clock
+}
+
+// NewEvaluator creates an evaluator, a nil clock uses time.Now
+func NewEvaluator(clock Clock) *Evaluator {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &Evaluator{Now: clock}
+}
+
+// Match returns the index of the first window containing the current time, or -1 if none does
+func (e *Evaluator) Match(windows []Window) int {
+	now := e.Now()
+	for i, window := range windows {
+		if window.Contains(now) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fixedClock returns a clock that always reports the given time
+func fixedClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+func mustWindow(t *testing.T, days []string, start, end, timezone string) Window {
+	t.Helper()
+	window, err := ParseWindow(days, start, end, timezone)
+	if err != nil {
+		t.Fatalf("ParseWindow(%v, %q, %q, %q) returned error: %v", days, start, end, timezone, err)
+	}
+	return window
+}
+
+func TestParseWindowErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		days     []string
+		start    string
+		end      string
+		timezone string
+		want     error
+	}{
+		{"no days", nil, "09:00", "17:00", "UTC", ErrInvalidDay},
+		{"unknown day", []string{"mon", "funday"}, "09:00", "17:00", "UTC", ErrInvalidDay},
+		{"bad start", []string{"mon"}, "9am", "17:00", "UTC", ErrInvalidTime},
+		{"bad end", []string{"mon"}, "09:00", "24:00", "UTC", ErrInvalidTime},
+		{"empty window", []string{"mon"}, "09:00", "09:00", "UTC", ErrEmptyWindow},
+		{"missing timezone", []string{"mon"}, "09:00", "17:00", "", ErrInvalidTimezone},
+		{"unknown timezone", []string{"mon"}, "09:00", "17:00", "Mars/Olympus", ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWindow(tt.days, tt.start, tt.end, tt.timezone)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchBusinessHours(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	windows := []Window{
+		mustWindow(t, []string{"mon", "tue", "wed", "thu", "fri"}, "09:00", "17:00", "America/New_York"),
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"weekday morning", time.Date(2024, 3, 4, 9, 0, 0, 0, newYork), 0},
+		{"weekday afternoon", time.Date(2024, 3, 8, 16, 59, 59, 0, newYork), 0},
+		{"end is exclusive", time.Date(2024, 3, 4, 17, 0, 0, 0, newYork), -1},
+		{"before opening", time.Date(2024, 3, 4, 8, 59, 0, 0, newYork), -1},
+		{"weekend", time.Date(2024, 3, 9, 12, 0, 0, 0, newYork), -1},
+		// 14:30 UTC is 09:30 in New York on a Monday
+		{"clock in another zone", time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC), 0},
+		// 13:30 UTC is 08:30 in New York before DST and 09:30 after it
+		{"before DST", time.Date(2024, 3, 8, 13, 30, 0, 0, time.UTC), -1},
+		{"after DST", time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewEvaluator(fixedClock(tt.now)).Match(windows); got != tt.want {
+				t.Fatalf("Match at %v = %d, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchOvernightWindow(t *testing.T) {
+	// Friday night shift running into Saturday morning
+	windows := []Window{mustWindow(t, []string{"fri"}, "22:00", "06:00", "UTC")}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"friday before start", time.Date(2024, 3, 8, 21, 59, 0, 0, time.UTC), -1},
+		{"friday night", time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC), 0},
+		{"saturday early", time.Date(2024, 3, 9, 5, 59, 0, 0, time.UTC), 0},
+		{"saturday after end", time.Date(2024, 3, 9, 6, 0, 0, 0, time.UTC), -1},
+		{"saturday night", time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC), -1},
+		{"friday early belongs to thursday", time.Date(2024, 3, 8, 1, 0, 0, 0, time.UTC), -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewEvaluator(fixedClock(tt.now)).Match(windows); got != tt.want {
+				t.Fatalf("Match at %v = %d, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFirstWindowWins(t *testing.T) {
+	windows := []Window{
+		mustWindow(t, []string{"mon"}, "12:00", "13:00", "UTC"),
+		mustWindow(t, []string{"mon"}, "09:00", "17:00", "UTC"),
+	}
+
+	lunch := NewEvaluator(fixedClock(time.Date(2024, 3, 4, 12, 30, 0, 0, time.UTC)))
+	if got := lunch.Match(windows); got != 0 {
+		t.Fatalf("Match during lunch = %d, want 0", got)
+	}
+
+	afternoon := NewEvaluator(fixedClock(time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)))
+	if got := afternoon.Match(windows); got != 1 {
+		t.Fatalf("Match in the afternoon = %d, want 1", got)
+	}
+
+	if got := afternoon.Match(nil); got != -1 {
+		t.Fatalf("Match without windows = %d, want -1", got)
+	}
+}
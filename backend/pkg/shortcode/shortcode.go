@@ -0,0 +1,164 @@
+// Package shortcode generates random short codes over a configurable alphabet. Every character is drawn
+// uniformly from the alphabet, candidates are checked for uniqueness, and the length grows by itself once
+// collisions show the keyspace at the current length is filling up.
+package shortcode
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Alphabets
+const (
+	AlphabetBase62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// AlphabetUnambiguous is base62 without the look-alike characters 0, O, o, 1, l and I
+	AlphabetUnambiguous = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// Generator defaults and limits
+const (
+	DefaultAlphabet = AlphabetUnambiguous
+	DefaultLength   = 6
+	DefaultAttempts = 3 // Collisions at a length before it grows
+	MinLength       = 4
+	MaxLength       = 32
+)
+
+// Errors returned by the generator
+var (
+	ErrInvalidAlphabet = errors.New("alphabet must have at least 2 distinct ASCII letters or digits")
+	ErrInvalidLength   = fmt.Errorf("length must be between %d and %d", MinLength, MaxLength)
+	ErrExhausted       = errors.New("no unused short code found")
+)
+
+// ExistsFunc reports whether a short code is already taken
+type ExistsFunc func(code string) (bool, error)
+
+// Generator generates short codes, it is safe for concurrent use
+type Generator struct {
+	Alphabet  string
+	MaxLength int       // Length the codes never grow past
+	Attempts  int       // Collisions at a length before it grows
+	Random    io.Reader // Source of randomness, crypto/rand by default
+
+	mu    sync.Mutex
+	grown map[string]int // Length each scope has grown to
+}
+
+// New creates a generator over the alphabet, an empty alphabet is the default one
+func New(alphabet string) (*Generator, error) {
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	if err := ValidateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		Alphabet:  alphabet,
+		MaxLength: MaxLength,
+		Attempts:  DefaultAttempts,
+		Random:    rand.Reader,
+	}, nil
+}
+
+// ValidateAlphabet checks that the alphabet only has distinct letters and digits, so any code is a valid slug
+func ValidateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return ErrInvalidAlphabet
+	}
+
+	seen := make(map[byte]bool, len(alphabet))
+	for i := 0; i < len(alphabet); i++ {
+		char := alphabet[i]
+		isAlphanumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlphanumeric || seen[char] {
+			return ErrInvalidAlphabet
+		}
+		seen[char] = true
+	}
+	return nil
+}
+
+// ValidateLength checks that the length is supported
+func ValidateLength(length int) error {
+	if length < MinLength || length > MaxLength {
+		return ErrInvalidLength
+	}
+	return nil
+}
+
+// Code returns a random code of the length, without checking whether it is taken.
+// Bytes that would favour the first characters of the alphabet are rejected, so every character is equally likely.
+func (generator *Generator) Code(length int) (string, error) {
+	size := len(generator.Alphabet)
+	limit := 256 - 256%size // The largest multiple of the alphabet size a byte can hold
+
+	code := make([]byte, 0, length)
+	buffer := make([]byte, length+length/2)
+	for len(code) < length {
+		if _, err := io.ReadFull(generator.Random, buffer); err != nil {
+			return "", err
+		}
+		for _, value := range buffer {
+			if int(value) >= limit {
+				continue
+			}
+			code = append(code, generator.Alphabet[int(value)%size])
+			if len(code) == length {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// Generate returns a code that exists reports as unused. Codes of a scope, like a domain, start at the
+// given length or the length the scope has grown to. Once a length gives Attempts collisions in a row
+// the scope grows by one character, and it keeps that length for the next codes.
+func (generator *Generator) Generate(scope string, length int, exists ExistsFunc) (string, error) {
+	if err := ValidateLength(length); err != nil {
+		return "", err
+	}
+
+	length = generator.Length(scope, length)
+	for ; length <= generator.MaxLength; length++ {
+		for range max(generator.Attempts, 1) {
+			code, err := generator.Code(length)
+			if err != nil {
+				return "", err
+			}
+
+			taken, err := exists(code)
+			if err != nil {
+				return "", err
+			}
+			if !taken {
+				return code, nil
+			}
+		}
+		generator.grow(scope, length+1)
+	}
+
+	return "", ErrExhausted
+}
+
+// Length returns the length the codes of the scope start at, at least the given one
+func (generator *Generator) Length(scope string, length int) int {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	return max(length, generator.grown[scope])
+}
+
+// grow remembers that the codes of the scope need at least the length
+func (generator *Generator) grow(scope string, length int) {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	if generator.grown == nil {
+		generator.grown = make(map[string]int)
+	}
+	generator.grown[scope] = max(generator.grown[scope], min(length, generator.MaxLength))
+}
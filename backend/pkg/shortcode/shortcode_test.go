@@ -0,0 +1,256 @@
+package shortcode
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// seededGenerator returns a generator with a deterministic source of randomness
+func seededGenerator(t *testing.T, alphabet string, seed byte) *Generator {
+	t.Helper()
+	generator, err := New(alphabet)
+	if err != nil {
+		t.Fatalf("New(%q) returned error: %v", alphabet, err)
+	}
+	generator.Random = rand.NewChaCha8([32]byte{seed})
+	return generator
+}
+
+// cyclingReader returns every byte value in turn
+type cyclingReader struct{ next byte }
+
+func (reader *cyclingReader) Read(buffer []byte) (int, error) {
+	for i := range buffer {
+		buffer[i] = reader.next
+		reader.next++
+	}
+	return len(buffer), nil
+}
+
+func TestValidateAlphabet(t *testing.T) {
+	tests := []struct {
+		alphabet string
+		valid    bool
+	}{
+		{AlphabetBase62, true},
+		{AlphabetUnambiguous, true},
+		{"ab", true},
+		{"a", false},
+		{"abca", false},
+		{"abc-", false},
+		{"abc_", false},
+		{"abc.", false},
+		{"abcé", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateAlphabet(tt.alphabet); (err == nil) != tt.valid {
+			t.Errorf("ValidateAlphabet(%q) = %v, want valid %t", tt.alphabet, err, tt.valid)
+		}
+	}
+}
+
+func TestUnambiguousAlphabetHasNoLookAlikes(t *testing.T) {
+	if strings.ContainsAny(AlphabetUnambiguous, "0Oo1lI") {
+		t.Fatal("the unambiguous alphabet contains look-alike characters")
+	}
+	if len(AlphabetUnambiguous) != len(AlphabetBase62)-6 {
+		t.Fatalf("got %d characters, want base62 without the 6 look-alikes", len(AlphabetUnambiguous))
+	}
+}
+
+// Every code has the requested length and only characters of the alphabet, whatever the alphabet
+func TestCodePropertyLengthAndCharacters(t *testing.T) {
+	property := func(seed byte, alphabetSize, length uint8) bool {
+		alphabet := AlphabetBase62[:2+int(alphabetSize)%(len(AlphabetBase62)-1)]
+		length = MinLength + length%(MaxLength-MinLength+1)
+
+		generator := seededGenerator(t, alphabet, seed)
+		code, err := generator.Code(int(length))
+		if err != nil || len(code) != int(length) {
+			return false
+		}
+		for _, char := range code {
+			if !strings.ContainsRune(alphabet, char) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Over a full cycle of byte values every character comes up exactly as often, the bytes that would
+// favour the first characters are rejected
+func TestCodeHasNoModuloBias(t *testing.T) {
+	for _, alphabet := range []string{AlphabetBase62, AlphabetUnambiguous, "abc"} {
+		generator, err := New(alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generator.Random = &cyclingReader{}
+
+		accepted := 256 - 256%len(alphabet)
+		code, err := generator.Code(accepted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts := make(map[rune]int)
+		for _, char := range code {
+			counts[char]++
+		}
+		want := accepted / len(alphabet)
+		for _, char := range alphabet {
+			if counts[char] != want {
+				t.Fatalf("alphabet %q: %q came up %d times, want %d", alphabet, char, counts[char], want)
+			}
+		}
+	}
+}
+
+// The characters at every position of the codes are uniformly distributed, checked with a chi-squared test
+func TestCodeDistributionIsUniform(t *testing.T) {
+	const codes, length = 30000, 8
+	// Critical value of the chi-squared distribution with 55 degrees of freedom at p = 0.001
+	const critical = 93.17
+
+	generator := seededGenerator(t, AlphabetUnambiguous, 42)
+	size := len(AlphabetUnambiguous)
+
+	counts := make([][]int, length)
+	for position := range counts {
+		counts[position] = make([]int, size)
+	}
+	for range codes {
+		code, err := generator.Code(length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for position := range length {
+			counts[position][strings.IndexByte(AlphabetUnambiguous, code[position])]++
+		}
+	}
+
+	expected := float64(codes) / float64(size)
+	for position, positionCounts := range counts {
+		var chiSquared float64
+		for _, count := range positionCounts {
+			diff := float64(count) - expected
+			chiSquared += diff * diff / expected
+		}
+		if chiSquared > critical {
+			t.Errorf("position %d: chi-squared %.1f exceeds %.1f, the characters are not uniform", position, chiSquared, critical)
+		}
+	}
+}
+
+// Random codes rarely collide while the keyspace is mostly empty
+func TestCodeCollisionsMatchKeyspace(t *testing.T) {
+	const codes = 20000
+	generator := seededGenerator(t, "abcdefghij", 7)
+
+	// 10^6 codes of length 6, about codes²/2·10⁶ = 200 collisions are expected
+	seen := make(map[string]bool, codes)
+	collisions := 0
+	for range codes {
+		code, err := generator.Code(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[code] {
+			collisions++
+		}
+		seen[code] = true
+	}
+	if collisions < 120 || collisions > 300 {
+		t.Fatalf("got %d collisions, want about 200", collisions)
+	}
+}
+
+func TestGenerateReturnsUnusedCode(t *testing.T) {
+	generator := seededGenerator(t, AlphabetUnambiguous, 1)
+	taken := make(map[string]bool)
+
+	for range 1000 {
+		code, err := generator.Generate("domain", DefaultLength, func(code string) (bool, error) {
+			return taken[code], nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if taken[code] {
+			t.Fatalf("got taken code %q", code)
+		}
+		taken[code] = true
+	}
+}
+
+func TestGenerateGrowsWhenKeyspaceFills(t *testing.T) {
+	generator := seededGenerator(t, "ab", 3)
+
+	// Every code of length 4 is taken, the generator has to grow
+	exists := func(code string) (bool, error) {
+		return len(code) == 4, nil
+	}
+
+	code, err := generator.Generate("full", 4, exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 5 {
+		t.Fatalf("got code %q, want length 5", code)
+	}
+
+	// The scope keeps its grown length, other scopes don't
+	if got := generator.Length("full", 4); got != 5 {
+		t.Fatalf("scope length %d, want 5", got)
+	}
+	if got := generator.Length("other", 4); got != 4 {
+		t.Fatalf("other scope length %d, want 4", got)
+	}
+
+	// A longer configured length wins over the grown one
+	if got := generator.Length("full", 8); got != 8 {
+		t.Fatalf("scope length %d, want the configured 8", got)
+	}
+}
+
+func TestGenerateExhausted(t *testing.T) {
+	generator := seededGenerator(t, "ab", 5)
+	generator.MaxLength = 6
+
+	calls := 0
+	_, err := generator.Generate("scope", 4, func(string) (bool, error) {
+		calls++
+		return true, nil
+	})
+	if !errors.Is(err, ErrExhausted) {
+		t.Fatalf("got error %v, want ErrExhausted", err)
+	}
+	if want := 3 * DefaultAttempts; calls != want {
+		t.Fatalf("got %d uniqueness checks, want %d", calls, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	generator := seededGenerator(t, "", 9)
+
+	if _, err := generator.Generate("scope", MinLength-1, nil); !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("got error %v, want ErrInvalidLength", err)
+	}
+
+	lookupErr := errors.New("database down")
+	_, err := generator.Generate("scope", DefaultLength, func(string) (bool, error) {
+		return false, lookupErr
+	})
+	if !errors.Is(err, lookupErr) {
+		t.Fatalf("got error %v, want the lookup error", err)
+	}
+}
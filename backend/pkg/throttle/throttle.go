@@ -0,0 +1,160 @@
+// Package throttle slows down guessing. After a few attempts for a key, like a link or a client IP, further
+// attempts are refused or delayed for a time that doubles with every attempt, until a successful one resets
+// the key. Keys without an attempt for a while are forgotten, and the number of keys tracked at once is bounded.
+package throttle
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Default limiter settings
+const (
+	DefaultForget  = time.Hour
+	DefaultMaxKeys = 100000
+)
+
+// Limiter tracks the attempts of each key, it is safe for concurrent use. Every attempt counts as a failure
+// until the key is reset, so concurrent attempts can't all get past the limit before their results are known.
+type Limiter struct {
+	Free    int           // Attempts allowed before further ones are delayed
+	Base    time.Duration // Delay after the first attempt past Free, doubled with each further one
+	Max     time.Duration // Longest delay
+	Forget  time.Duration // Attempts are forgotten after this long without a new one
+	MaxKeys int           // Keys tracked at once, the least recently attempted one is dropped first
+	Now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Entries from the least to the most recently attempted
+}
+
+// entry is the attempt history of a key
+type entry struct {
+	key           string
+	attempts      int
+	lastAttempted time.Time
+	retryAt       time.Time // Allow refuses attempts before this time
+}
+
+// New creates a limiter allowing free attempts, then delaying further ones from base up to max
+func New(free int, base, max time.Duration) *Limiter {
+	return &Limiter{
+		Free:    free,
+		Base:    base,
+		Max:     max,
+		Forget:  DefaultForget,
+		MaxKeys: DefaultMaxKeys,
+		Now:     time.Now,
+	}
+}
+
+// Allow reserves an attempt for the key when one may be made now, and otherwise reports how long to wait
+func (limiter *Limiter) Allow(key string) (time.Duration, bool) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.Now()
+	current := limiter.entry(key, now)
+	if wait := current.retryAt.Sub(now); wait > 0 {
+		return wait, false
+	}
+
+	if over := limiter.attempt(current, now); over > 0 {
+		current.retryAt = now.Add(limiter.delay(over))
+	}
+	return 0, true
+}
+
+// Delay reserves an attempt for the key and returns how long to wait before making it. Attempts are never
+// refused, so guesses from elsewhere can slow the key down but not lock it out.
+func (limiter *Limiter) Delay(key string) time.Duration {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.Now()
+	if over := limiter.attempt(limiter.entry(key, now), now); over > 0 {
+		return limiter.delay(over)
+	}
+	return 0
+}
+
+// Reset forgets the attempts of the key, after a successful one
+func (limiter *Limiter) Reset(key string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if element, ok := limiter.entries[key]; ok {
+		limiter.order.Remove(element)
+		delete(limiter.entries, key)
+	}
+}
+
+// Len returns the number of keys tracked
+func (limiter *Limiter) Len() int {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return len(limiter.entries)
+}
+
+// entry returns the history of the key, starting a new one when it has none or it was forgotten
+func (limiter *Limiter) entry(key string, now time.Time) *entry {
+	if limiter.entries == nil {
+		limiter.entries = make(map[string]*list.Element)
+		limiter.order = list.New()
+	}
+
+	if element, ok := limiter.entries[key]; ok {
+		current := element.Value.(*entry)
+		if !limiter.expired(current, now) {
+			return current
+		}
+		limiter.order.Remove(element)
+		delete(limiter.entries, key)
+	}
+
+	limiter.makeRoom(now)
+	current := &entry{key: key, lastAttempted: now}
+	limiter.entries[key] = limiter.order.PushBack(current)
+	return current
+}
+
+// attempt records an attempt for the entry and returns the number of attempts past the free ones
+func (limiter *Limiter) attempt(current *entry, now time.Time) int {
+	current.attempts++
+	current.lastAttempted = now
+	limiter.order.MoveToBack(limiter.entries[current.key])
+	return current.attempts - limiter.Free
+}
+
+// delay returns the delay after the given number of attempts past the free ones
+func (limiter *Limiter) delay(over int) time.Duration {
+	delay := limiter.Base
+	for i := 1; i < over && delay < limiter.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, limiter.Max)
+}
+
+// expired reports whether the attempts of the entry are old enough to be forgotten
+func (limiter *Limiter) expired(current *entry, now time.Time) bool {
+	return now.Sub(current.lastAttempted) >= limiter.Forget && !now.Before(current.retryAt)
+}
+
+// makeRoom drops the forgotten keys at the front of the order, then the least recently attempted key
+// while the limiter is full
+func (limiter *Limiter) makeRoom(now time.Time) {
+	for front := limiter.order.Front(); front != nil && limiter.expired(front.Value.(*entry), now); front = limiter.order.Front() {
+		limiter.drop(front)
+	}
+	for limiter.MaxKeys > 0 && len(limiter.entries) >= limiter.MaxKeys {
+		limiter.drop(limiter.order.Front())
+	}
+}
+
+// drop stops tracking the key of the element
+func (limiter *Limiter) drop(element *list.Element) {
+	limiter.order.Remove(element)
+	delete(limiter.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,179 @@
+package throttle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock the tests move by hand
+type fakeClock struct{ now time.Time }
+
+func (clock *fakeClock) Now() time.Time { return clock.now }
+
+// newTestLimiter returns a limiter on a fake clock
+func newTestLimiter(free int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := New(free, time.Second, time.Minute)
+	limiter.Now = clock.Now
+	return limiter, clock
+}
+
+func TestFreeAttempts(t *testing.T) {
+	limiter, _ := newTestLimiter(3)
+
+	for i := range 4 {
+		if _, ok := limiter.Allow("link"); !ok {
+			t.Fatalf("attempt %d refused after the free attempts only", i+1)
+		}
+	}
+
+	// The attempt past the free ones delays the next one
+	wait, ok := limiter.Allow("link")
+	if ok || wait != time.Second {
+		t.Fatalf("got allowed %t wait %v after the free attempts, want a wait of 1s", ok, wait)
+	}
+
+	// Other keys are unaffected
+	if _, ok := limiter.Allow("other"); !ok {
+		t.Fatal("another key was refused")
+	}
+}
+
+func TestConcurrentAttemptsAreReserved(t *testing.T) {
+	limiter, _ := newTestLimiter(5)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := limiter.Allow("ip"); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The free attempts and the one that starts the delay
+	if allowed != 6 {
+		t.Fatalf("got %d concurrent attempts allowed, want 6", allowed)
+	}
+}
+
+func TestDelayDoublesUpToMax(t *testing.T) {
+	limiter, clock := newTestLimiter(0)
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, time.Minute, time.Minute}
+	for i, delay := range want {
+		if _, ok := limiter.Allow("ip"); !ok {
+			t.Fatalf("attempt %d refused once the delay passed", i+1)
+		}
+		wait, ok := limiter.Allow("ip")
+		if ok || wait != delay {
+			t.Fatalf("attempt %d: got allowed %t wait %v, want %v", i+1, ok, wait, delay)
+		}
+		clock.now = clock.now.Add(wait)
+	}
+}
+
+func TestDelayNeverRefuses(t *testing.T) {
+	limiter, _ := newTestLimiter(2)
+
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for i, delay := range want {
+		if got := limiter.Delay("link"); got != delay {
+			t.Fatalf("attempt %d: got delay %v, want %v", i+1, got, delay)
+		}
+	}
+	for range 20 {
+		limiter.Delay("link")
+	}
+	if got := limiter.Delay("link"); got != limiter.Max {
+		t.Fatalf("got delay %v, want it capped at %v", got, limiter.Max)
+	}
+}
+
+func TestResetAndForget(t *testing.T) {
+	limiter, clock := newTestLimiter(0)
+
+	limiter.Allow("ip")
+	limiter.Reset("ip")
+	if _, ok := limiter.Allow("ip"); !ok {
+		t.Fatal("attempt refused after a reset")
+	}
+
+	limiter.Delay("ip")
+	clock.now = clock.now.Add(limiter.Forget)
+	if got := limiter.Delay("ip"); got != time.Second {
+		t.Fatalf("got delay %v, want the forgotten attempts to start over at 1s", got)
+	}
+}
+
+func TestKeysAreBounded(t *testing.T) {
+	limiter, clock := newTestLimiter(0)
+	limiter.MaxKeys = 10
+
+	for i := range 100 {
+		limiter.Allow(fmt.Sprintf("ip-%d", i))
+		clock.now = clock.now.Add(time.Millisecond)
+	}
+	if got := limiter.Len(); got > limiter.MaxKeys {
+		t.Fatalf("got %d keys, want at most %d", got, limiter.MaxKeys)
+	}
+
+	// The most recent attempts are kept
+	if _, ok := limiter.Allow("ip-99"); ok {
+		t.Fatal("the most recent key was dropped")
+	}
+	if _, ok := limiter.Allow("ip-0"); !ok {
+		t.Fatal("the oldest key was kept")
+	}
+}
+
+func TestLeastRecentlyAttemptedKeyIsDropped(t *testing.T) {
+	limiter, clock := newTestLimiter(0)
+	limiter.MaxKeys = 3
+
+	for _, key := range []string{"a", "b", "c"} {
+		limiter.Delay(key)
+		clock.now = clock.now.Add(time.Millisecond)
+	}
+	limiter.Delay("a") // a is now the most recent
+	limiter.Delay("d")
+
+	if got := limiter.Len(); got != 3 {
+		t.Fatalf("got %d keys, want 3", got)
+	}
+	if got := limiter.Delay("a"); got != 4*time.Second {
+		t.Fatalf("got delay %v for a, want its attempts kept", got)
+	}
+	if got := limiter.Delay("b"); got != time.Second {
+		t.Fatalf("got delay %v for b, want it dropped and started over", got)
+	}
+}
+
+func TestForgottenKeysAreDroppedFirst(t *testing.T) {
+	limiter, clock := newTestLimiter(0)
+	limiter.MaxKeys = 3
+
+	limiter.Delay("old")
+	clock.now = clock.now.Add(limiter.Forget)
+	limiter.Allow("a")
+	limiter.Allow("b")
+	limiter.Allow("c")
+
+	if got := limiter.Len(); got != 3 {
+		t.Fatalf("got %d keys, want 3", got)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if _, ok := limiter.Allow(key); ok {
+			t.Fatalf("key %q was dropped instead of the forgotten one", key)
+		}
+	}
+}
@@ -18,6 +18,7 @@ const (
 const (
 	ErrResourceNotFound = "resource_not_found"
 	ErrResourceGone     = "resource_expired"
+	ErrResourceInactive = "resource_not_active"
 	ErrResourceExists   = "resource_already_exists"
 )
 
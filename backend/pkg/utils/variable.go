@@ -17,6 +17,12 @@ var (
 	FrontendURl              string
 	GiteaORGName             string
 	GiteaCommitEmail         string
+	// Unit is days, trashed URLs are purged after it
+	TrashRetentionDays int
+	// Characters of generated short codes, empty for the default alphabet
+	ShortCodeAlphabet string
+	// Length of generated short codes, unless the workspace or domain sets another one
+	ShortCodeLength int
 )
 
 // Init some usefil variables
@@ -27,6 +33,12 @@ func init() {
 	BackendURL = fmt.Sprintf("%s/api/v%s", os.Getenv("BASE_URL"), os.Getenv("VERSION"))
 	FrontendURl = os.Getenv("BASE_URL")
 	GiteaCommitEmail = os.Getenv("GITEA_COMMIT_EMAIL")
+	TrashRetentionDays = Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if TrashRetentionDays <= 0 {
+		TrashRetentionDays = 30
+	}
+	ShortCodeAlphabet = os.Getenv("SHORT_CODE_ALPHABET")
+	ShortCodeLength = Atoi(os.Getenv("SHORT_CODE_LENGTH"))
 }
 
 // Magic bytes for different image formats
@@ -38,6 +38,9 @@ export default function DashboardPage() {
     null
   );
   const [isLoadingLinks, setIsLoadingLinks] = React.useState(true);
+  const [isLoadingMore, setIsLoadingMore] = React.useState(false);
+  const [nextCursor, setNextCursor] = React.useState<string | null>(null);
+  const [totalLinks, setTotalLinks] = React.useState(0);
   const [domains] = React.useState<DomainData[]>([]);
   // const [isLoadingDomains, setIsLoadingDomains] = React.useState(false); // Unused for now
   
@@ -80,16 +83,27 @@ export default function DashboardPage() {
   //   }
   // };
 
-  const fetchLinks = React.useCallback(async () => {
-    setIsLoadingLinks(true);
+  // Fetches the first page of links, or the page after the cursor which is appended to the list
+  const fetchLinks = React.useCallback(async (cursor?: string) => {
+    if (cursor) {
+      setIsLoadingMore(true);
+    } else {
+      setIsLoadingLinks(true);
+    }
     try {
-      const response = await fetch(API_URLS.URL.LIST(workspaceId));
+      const listUrl = cursor
+        ? `${API_URLS.URL.LIST(workspaceId)}?cursor=${encodeURIComponent(cursor)}`
+        : API_URLS.URL.LIST(workspaceId);
+      const response = await fetch(listUrl);
       if (!response.ok) {
         throw new Error("Failed to fetch links");
       }
       const data = await response.json();
-      if (data) {
-        const formattedLinks = data.map((link: {
+      const urls = data?.result?.urls;
+      setNextCursor(data?.result?.next_cursor || null);
+      setTotalLinks(data?.result?.total || 0);
+      if (urls) {
+        const formattedLinks = urls.map((link: {
           id: string;
           short_code: string;
           original_url: string;
@@ -110,8 +124,10 @@ export default function DashboardPage() {
             ? new Date(link.expires_at).toISOString()
             : undefined,
         }));
-        setLinks(formattedLinks);
-      } else {
+        setLinks((previous) =>
+          cursor ? [...previous, ...formattedLinks] : formattedLinks
+        );
+      } else if (!cursor) {
         setLinks([]);
       }
     } catch (error) {
@@ -119,6 +135,7 @@ export default function DashboardPage() {
       toast.error(t("links.fetchError"));
     } finally {
       setIsLoadingLinks(false);
+      setIsLoadingMore(false);
     }
   }, [workspaceId, t]);
 
@@ -132,7 +149,7 @@ export default function DashboardPage() {
   // Calculate workspace info based on actual data
   const workspaceInfo = {
     name: workspaceTitle,
-    totalLinks: links.length,
+    totalLinks: Math.max(totalLinks, links.length),
     totalClicks: links.reduce((sum, link) => sum + link.clicks, 0),
   };
 
@@ -344,6 +361,21 @@ export default function DashboardPage() {
               </div>
             ))
           )}
+
+          {!isLoadingLinks && nextCursor && (
+            <div className="flex justify-center">
+              <Button
+                variant="outline"
+                onClick={() => fetchLinks(nextCursor)}
+                disabled={isLoadingMore}
+              >
+                {isLoadingMore && (
+                  <Icon icon="lucide:loader-2" className="mr-2 h-4 w-4 animate-spin" />
+                )}
+                {t("links.loadMore")}
+              </Button>
+            </div>
+          )}
         </div>
       </div>
 